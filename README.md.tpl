@@ -43,6 +43,20 @@ import (
 func main() {{ "ExampleReader" | code }}
 ```
 
+## Command-line tool
+
+The `sntable` command allows to inspect and debug table files:
+
+```sh
+$ go get github.com/bsm/sntable/cmd/sntable
+
+$ sntable info mystore.snt
+$ sntable get mystore.snt 101
+$ sntable scan --from 100 --to 200 mystore.snt
+$ sntable dump-blocks mystore.snt
+$ sntable verify mystore.snt
+```
+
 ## Stats, Lies, Benchmarks
 
 ```sh
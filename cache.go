@@ -0,0 +1,127 @@
+package sntable
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+const numCacheShards = 16
+
+// BlockCache is an LRU cache for decompressed data blocks. A single
+// cache can be shared by many readers. Please see ReaderOptions.
+type BlockCache struct {
+	lastID uint64 // must be 64-bit aligned
+	shards [numCacheShards]cacheShard
+}
+
+// NewBlockCache inits a new cache with a capacity of (roughly) the given
+// number of bytes.
+func NewBlockCache(capacity int64) *BlockCache {
+	c := new(BlockCache)
+	for i := range c.shards {
+		c.shards[i].capacity = capacity / numCacheShards
+		c.shards[i].items = make(map[cacheKey]*list.Element)
+	}
+	return c
+}
+
+// Size returns the number of bytes currently used by the cache.
+func (c *BlockCache) Size() int64 {
+	var sum int64
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		sum += s.size
+		s.mu.Unlock()
+	}
+	return sum
+}
+
+// newID returns a new, unique table ID.
+func (c *BlockCache) newID() uint64 {
+	return atomic.AddUint64(&c.lastID, 1)
+}
+
+func (c *BlockCache) get(key cacheKey) ([]byte, bool) {
+	return c.shard(key).get(key)
+}
+
+func (c *BlockCache) add(key cacheKey, block []byte) bool {
+	return c.shard(key).add(key, block)
+}
+
+func (c *BlockCache) shard(key cacheKey) *cacheShard {
+	h := (key.table*0x9e3779b97f4a7c15 + uint64(key.bpos)) * 0xc4ceb9fe1a85ec53
+	return &c.shards[(h>>32)%numCacheShards]
+}
+
+// --------------------------------------------------------------------
+
+type cacheKey struct {
+	table uint64 // the table ID
+	bpos  int    // the block position
+}
+
+type cacheEntry struct {
+	key   cacheKey
+	block []byte
+}
+
+type cacheShard struct {
+	mu       sync.Mutex
+	lru      list.List
+	items    map[cacheKey]*list.Element
+	size     int64
+	capacity int64
+}
+
+func (s *cacheShard) get(key cacheKey) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	s.lru.MoveToFront(el)
+	return el.Value.(*cacheEntry).block, true
+}
+
+// add adds a block to the cache and returns true if successful. Once added,
+// blocks are owned by the cache and must not be modified or released.
+func (s *cacheShard) add(key cacheKey, block []byte) bool {
+	sz := int64(cap(block))
+	if sz > s.capacity {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[key]; ok {
+		return false
+	}
+
+	for s.size+sz > s.capacity {
+		s.evict()
+	}
+
+	s.items[key] = s.lru.PushFront(&cacheEntry{key: key, block: block})
+	s.size += sz
+	return true
+}
+
+// evict removes the least recently used entry. Evicted blocks may still be
+// referenced by block readers and are therefore simply left to the GC.
+func (s *cacheShard) evict() {
+	el := s.lru.Back()
+	if el == nil {
+		return
+	}
+
+	ent := s.lru.Remove(el).(*cacheEntry)
+	delete(s.items, ent.key)
+	s.size -= int64(cap(ent.block))
+}
@@ -0,0 +1,90 @@
+package sntable_test
+
+import (
+	"bytes"
+
+	"github.com/bsm/sntable"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BlockCache", func() {
+	var subject *sntable.BlockCache
+	var data []byte
+
+	open := func() *sntable.Reader {
+		r, err := sntable.NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), &sntable.ReaderOptions{BlockCache: subject})
+		Expect(err).NotTo(HaveOccurred())
+		return r
+	}
+
+	BeforeEach(func() {
+		buf := new(bytes.Buffer)
+		Expect(seedTable(buf, 1000)).To(Succeed())
+		data = buf.Bytes()
+
+		subject = sntable.NewBlockCache(1 << 20)
+	})
+
+	It("should cache blocks", func() {
+		r := open()
+		Expect(subject.Size()).To(BeZero())
+
+		Expect(r.Get(400)).To(HaveSuffix("0400"))
+		Expect(subject.Size()).To(BeNumerically("~", 4200, 200))
+
+		Expect(r.Get(404)).To(HaveSuffix("0404"))
+		Expect(subject.Size()).To(BeNumerically("~", 4200, 200))
+
+		Expect(r.Get(1200)).To(HaveSuffix("1200"))
+		Expect(subject.Size()).To(BeNumerically("~", 8400, 400))
+	})
+
+	It("should be shared across readers", func() {
+		r1, r2 := open(), open()
+		Expect(r1.Get(400)).To(HaveSuffix("0400"))
+		Expect(r2.Get(400)).To(HaveSuffix("0400"))
+		Expect(r2.Get(1200)).To(HaveSuffix("1200"))
+		Expect(subject.Size()).To(BeNumerically("~", 12600, 600))
+	})
+
+	It("should evict blocks", func() {
+		subject = sntable.NewBlockCache(16 * 8192)
+		r := open()
+
+		iter, err := r.Seek(0)
+		Expect(err).NotTo(HaveOccurred())
+		defer iter.Release()
+
+		for iter.Next() {
+			Expect(iter.Value()).To(HaveLen(128))
+		}
+		Expect(iter.Err()).NotTo(HaveOccurred())
+		Expect(subject.Size()).To(BeNumerically("<=", 16*8192))
+		Expect(subject.Size()).To(BeNumerically(">", 8*4096))
+	})
+
+	It("should not release cached blocks", func() {
+		r := open()
+		for i := 0; i < 3; i++ {
+			b, err := r.GetBlock(1)
+			Expect(err).NotTo(HaveOccurred())
+			b.Release()
+
+			// re-use pooled buffers
+			other, err := seedReader(100)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(other.Get(300)).To(HaveSuffix("0300"))
+		}
+
+		b, err := r.GetBlock(1)
+		Expect(err).NotTo(HaveOccurred())
+		defer b.Release()
+
+		s := b.GetSection(0)
+		defer s.Release()
+		Expect(s.Next()).To(BeTrue())
+		Expect(s.Key()).To(Equal(uint64(124)))
+		Expect(s.Value()).To(HaveSuffix("0124"))
+	})
+})
@@ -0,0 +1,274 @@
+// Command sntable allows to inspect and debug table files.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/bsm/sntable"
+)
+
+const usage = `Usage: sntable <command> [options] <file>
+
+Commands:
+  info         Print table information
+  get          Print the value of a key: sntable get <file> <key>
+  scan         Print entries: sntable scan [--from <key>] [--to <key>] <file>
+  dump-blocks  Print details of all blocks
+  verify       Verify table integrity
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err == flag.ErrHelp {
+		os.Exit(2)
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return flag.ErrHelp
+	}
+
+	cmd, args := args[0], args[1:]
+	fset := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fset.SetOutput(stderr)
+	fset.Usage = func() { fmt.Fprint(stderr, usage) }
+	from := fset.Uint64("from", 0, "Start key (inclusive)")
+	to := fset.Uint64("to", math.MaxUint64, "End key (inclusive)")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() == 0 {
+		fset.Usage()
+		return flag.ErrHelp
+	}
+
+	f, r, err := open(fset.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch cmd {
+	case "info":
+		return info(stdout, r)
+	case "get":
+		key, err := strconv.ParseUint(fset.Arg(1), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid key %q", fset.Arg(1))
+		}
+		return get(stdout, r, key)
+	case "scan":
+		return scan(stdout, r, *from, *to)
+	case "dump-blocks":
+		return dumpBlocks(stdout, r, f)
+	case "verify":
+		return verify(stdout, r)
+	default:
+		fset.Usage()
+		return flag.ErrHelp
+	}
+}
+
+func open(fname string) (*os.File, *sntable.Reader, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+
+	r, err := sntable.NewReader(f, fi.Size())
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+	return f, r, nil
+}
+
+func info(w io.Writer, r *sntable.Reader) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	ft := r.Footer()
+
+	fmt.Fprintf(tw, "format version:\t%d\n", ft.Version)
+	fmt.Fprintf(tw, "feature flags:\t%#x\n", ft.Features)
+	fmt.Fprintf(tw, "metaindex offset:\t%d\n", ft.MetaIndexOffset)
+	fmt.Fprintf(tw, "index offset:\t%d\n", ft.IndexOffset)
+	fmt.Fprintf(tw, "index size:\t%d\n", ft.Offset-ft.IndexOffset)
+	fmt.Fprintf(tw, "footer offset:\t%d\n", ft.Offset)
+	fmt.Fprintf(tw, "blocks:\t%d\n", r.NumBlocks())
+	if n := r.NumBlocks(); n != 0 {
+		fmt.Fprintf(tw, "first block max key:\t%d\n", r.BlockInfo(0).MaxKey)
+		fmt.Fprintf(tw, "last block max key:\t%d\n", r.BlockInfo(n-1).MaxKey)
+	}
+
+	if p := r.Properties(); p != nil {
+		fmt.Fprintf(tw, "entries:\t%d\n", p.NumEntries)
+		fmt.Fprintf(tw, "deletions:\t%d\n", p.NumDeletions)
+		fmt.Fprintf(tw, "min key:\t%d\n", p.MinKey)
+		fmt.Fprintf(tw, "max key:\t%d\n", p.MaxKey)
+		fmt.Fprintf(tw, "raw key size:\t%d\n", p.RawKeySize)
+		fmt.Fprintf(tw, "raw value size:\t%d\n", p.RawValueSize)
+		fmt.Fprintf(tw, "raw data size:\t%d\n", p.RawDataSize)
+		fmt.Fprintf(tw, "data size:\t%d\n", p.DataSize)
+		fmt.Fprintf(tw, "compression:\t%s\n", compressionName(p.Compression))
+		fmt.Fprintf(tw, "block size:\t%d\n", p.BlockSize)
+		fmt.Fprintf(tw, "block restart interval:\t%d\n", p.BlockRestartInterval)
+		fmt.Fprintf(tw, "filter bits per key:\t%d\n", p.FilterBitsPerKey)
+		fmt.Fprintf(tw, "created at:\t%s\n", p.CreatedAt)
+
+		names := make([]string, 0, len(p.UserProperties))
+		for name := range p.UserProperties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(tw, "%s:\t%q\n", name, p.UserProperties[name])
+		}
+	}
+	return tw.Flush()
+}
+
+func get(w io.Writer, r *sntable.Reader, key uint64) error {
+	val, err := r.Get(key)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%q\n", val)
+	return err
+}
+
+func scan(w io.Writer, r *sntable.Reader, from, to uint64) error {
+	iter, err := r.NewIterator(&sntable.IteratorOptions{
+		LowerBound: sntable.Inclusive(from),
+		UpperBound: sntable.Inclusive(to),
+	})
+	if err != nil {
+		return err
+	}
+	defer iter.Release()
+
+	for iter.Next() {
+		if iter.Kind() == sntable.KindDelete {
+			_, err = fmt.Fprintf(w, "%d\t(%s)\n", iter.Key(), iter.Kind())
+		} else {
+			_, err = fmt.Fprintf(w, "%d\t%q\n", iter.Key(), iter.Value())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+func dumpBlocks(w io.Writer, r *sntable.Reader, f io.ReaderAt) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "block\toffset\tstored size\tsize\tsections\tmax key\tcompression\tchecksum")
+
+	trailer := make([]byte, 1)
+	for bpos := 0; bpos < r.NumBlocks(); bpos++ {
+		info := r.BlockInfo(bpos)
+		if _, err := f.ReadAt(trailer, info.Offset+info.Length-1); err != nil {
+			return err
+		}
+
+		b, err := r.GetBlock(bpos)
+		if err != nil {
+			return fmt.Errorf("block #%d: %v", bpos, err)
+		}
+
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\t%d\t%s\t%v\n",
+			bpos,
+			info.Offset,
+			info.Length,
+			b.Size(),
+			b.NumSections(),
+			info.MaxKey,
+			blockCompressionName(trailer[0]&0x7f),
+			trailer[0]&0x80 != 0,
+		)
+		b.Release()
+	}
+	return tw.Flush()
+}
+
+func verify(w io.Writer, r *sntable.Reader) error {
+	var entries, deletions uint64
+	var last uint64
+
+	for bpos := 0; bpos < r.NumBlocks(); bpos++ {
+		b, err := r.GetBlock(bpos)
+		if err != nil {
+			return fmt.Errorf("block #%d: %v", bpos, err)
+		}
+
+		for spos := 0; spos < b.NumSections(); spos++ {
+			s := b.GetSection(spos)
+			for s.Next() {
+				if entries != 0 && s.Key() <= last {
+					s.Release()
+					b.Release()
+					return fmt.Errorf("block #%d, section #%d: key %d is out of order, must be > %d", bpos, spos, s.Key(), last)
+				}
+				last = s.Key()
+				entries++
+				if s.Kind() == sntable.KindDelete {
+					deletions++
+				}
+			}
+			s.Release()
+		}
+		b.Release()
+
+		if max := r.BlockInfo(bpos).MaxKey; last != max {
+			return fmt.Errorf("block #%d: last key %d does not match index key %d", bpos, last, max)
+		}
+	}
+
+	if p := r.Properties(); p != nil {
+		if p.NumEntries != entries {
+			return fmt.Errorf("number of entries %d does not match properties %d", entries, p.NumEntries)
+		}
+		if p.NumDeletions != deletions {
+			return fmt.Errorf("number of deletions %d does not match properties %d", deletions, p.NumDeletions)
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "OK: %d blocks, %d entries, %d deletions\n", r.NumBlocks(), entries, deletions)
+	return err
+}
+
+func compressionName(c sntable.Compression) string {
+	switch c {
+	case sntable.SnappyCompression:
+		return "snappy"
+	case sntable.NoCompression:
+		return "none"
+	}
+	return fmt.Sprintf("unknown (%d)", c)
+}
+
+func blockCompressionName(c byte) string {
+	switch c {
+	case 0:
+		return "none"
+	case 1:
+		return "snappy"
+	}
+	return fmt.Sprintf("unknown (%d)", c)
+}
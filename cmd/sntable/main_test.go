@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bsm/sntable"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("run", func() {
+	var dir, fname string
+
+	exec := func(args ...string) (string, error) {
+		buf := new(bytes.Buffer)
+		err := run(args, buf, ioutil.Discard)
+		return buf.String(), err
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "sntable-cmd-test")
+		Expect(err).NotTo(HaveOccurred())
+
+		fname = filepath.Join(dir, "test.snt")
+		f, err := os.Create(fname)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+
+		w := sntable.NewWriter(f, &sntable.WriterOptions{BlockSize: 256})
+		for key := uint64(10); key < 1000; key += 10 {
+			if key%100 == 20 {
+				Expect(w.Delete(key)).To(Succeed())
+				continue
+			}
+			Expect(w.Append(key, []byte(fmt.Sprintf("value %d", key)))).To(Succeed())
+		}
+		Expect(w.Close()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should print info", func() {
+		out, err := exec("info", fname)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(ContainSubstring("format version:          2\n"))
+		Expect(out).To(ContainSubstring("blocks:                  5\n"))
+		Expect(out).To(ContainSubstring("entries:                 99\n"))
+		Expect(out).To(ContainSubstring("deletions:               10\n"))
+		Expect(out).To(ContainSubstring("max key:                 990\n"))
+	})
+
+	It("should get", func() {
+		Expect(exec("get", fname, "550")).To(Equal("\"value 550\"\n"))
+
+		_, err := exec("get", fname, "555")
+		Expect(err).To(MatchError(sntable.ErrNotFound))
+
+		_, err = exec("get", fname, "520")
+		Expect(err).To(MatchError(sntable.ErrDeleted))
+	})
+
+	It("should scan", func() {
+		Expect(exec("scan", "--from", "300", "--to", "330", fname)).To(Equal(
+			"300\t\"value 300\"\n" +
+				"310\t\"value 310\"\n" +
+				"320\t(delete)\n" +
+				"330\t\"value 330\"\n",
+		))
+	})
+
+	It("should dump blocks", func() {
+		out, err := exec("dump-blocks", fname)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal(`block  offset  stored size  size  sections  max key  compression  checksum
+0      0       115          238   2         240      snappy       true
+1      115     119          245   2         470      snappy       true
+2      234     120          245   2         700      snappy       true
+3      354     116          236   2         930      snappy       true
+4      470     46           71    1         990      snappy       true
+`))
+	})
+
+	It("should verify", func() {
+		Expect(exec("verify", fname)).To(Equal("OK: 5 blocks, 99 entries, 10 deletions\n"))
+
+		data, err := ioutil.ReadFile(fname)
+		Expect(err).NotTo(HaveOccurred())
+		data[20] ^= 0xff
+		Expect(ioutil.WriteFile(fname, data, 0644)).To(Succeed())
+
+		_, err = exec("verify", fname)
+		Expect(err).To(MatchError(`block #0: sntable: corrupt block #0 at offset 0: checksum mismatch`))
+	})
+
+	It("should reject bad commands", func() {
+		_, err := exec("unknown", fname)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+// --------------------------------------------------------------------
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "sntable/cmd/sntable")
+}
@@ -0,0 +1,69 @@
+package sntable
+
+import "io"
+
+// CompactOptions define compaction specific options.
+type CompactOptions struct {
+	// WriterOptions are applied to the compacted table.
+	WriterOptions
+
+	// DropTombstones drops deletion markers from the compacted table.
+	// This is only safe when all tables which may contain older values
+	// of the deleted keys are part of the compaction.
+	DropTombstones bool
+
+	// Filter is an optional callback which is invoked for every (non-deleted)
+	// entry before it is written. It may return a rewritten value or drop
+	// the entry altogether by returning false.
+	Filter func(key uint64, value []byte) ([]byte, bool)
+}
+
+func (o *CompactOptions) norm() *CompactOptions {
+	var oo CompactOptions
+	if o != nil {
+		oo = *o
+	}
+	return &oo
+}
+
+// Compact merges multiple tables into a single new table which is written
+// to dst. Readers must be passed in order, from oldest to newest. If a key
+// exists in multiple tables, the value of the newest table wins.
+func Compact(dst io.Writer, o *CompactOptions, readers ...*Reader) error {
+	o = o.norm()
+
+	iter, err := MergeReaders(LastWins, nil, readers...)
+	if err != nil {
+		return err
+	}
+	defer iter.Release()
+
+	w := NewWriter(dst, &o.WriterOptions)
+	for iter.Next() {
+		key, val := iter.Key(), iter.Value()
+		if iter.Kind() == KindDelete {
+			if o.DropTombstones {
+				continue
+			}
+			if err := w.Delete(key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if o.Filter != nil {
+			var keep bool
+			if val, keep = o.Filter(key, val); !keep {
+				continue
+			}
+		}
+
+		if err := w.Append(key, val); err != nil {
+			return err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,102 @@
+package sntable_test
+
+import (
+	"bytes"
+
+	"github.com/bsm/sntable"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compact", func() {
+	var readers []*sntable.Reader
+
+	compact := func(o *sntable.CompactOptions) map[uint64]string {
+		buf := new(bytes.Buffer)
+		Expect(sntable.Compact(buf, o, readers...)).To(Succeed())
+
+		r, err := sntable.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		Expect(err).NotTo(HaveOccurred())
+		defer r.Close()
+
+		iter, err := r.Seek(0)
+		Expect(err).NotTo(HaveOccurred())
+		defer iter.Release()
+
+		res := make(map[uint64]string)
+		for iter.Next() {
+			if iter.Kind() == sntable.KindDelete {
+				res[iter.Key()] = "(deleted)"
+			} else {
+				res[iter.Key()] = string(iter.Value())
+			}
+		}
+		Expect(iter.Err()).NotTo(HaveOccurred())
+		return res
+	}
+
+	BeforeEach(func() {
+		readers = readers[:0]
+		for _, vals := range []map[uint64]string{
+			{1: "1a", 3: "3a", 5: "5a", 9: "9a"},
+			{2: "2b", 3: "3b", 9: "9b", 12: "12b"},
+			{3: "3c", 4: "4c", 12: "12c"},
+		} {
+			r, err := mapReader(vals)
+			Expect(err).NotTo(HaveOccurred())
+			readers = append(readers, r)
+		}
+	})
+
+	AfterEach(func() {
+		for _, r := range readers {
+			Expect(r.Close()).To(Succeed())
+		}
+	})
+
+	It("should merge tables (newest wins)", func() {
+		Expect(compact(nil)).To(Equal(map[uint64]string{
+			1: "1a", 2: "2b", 3: "3c", 4: "4c", 5: "5a", 9: "9b", 12: "12c",
+		}))
+	})
+
+	It("should keep tombstones", func() {
+		r, err := mapReader(map[uint64]string{5: "5d"}, 3, 9, 20)
+		Expect(err).NotTo(HaveOccurred())
+		readers = append(readers, r)
+
+		Expect(compact(nil)).To(Equal(map[uint64]string{
+			1: "1a", 2: "2b", 3: "(deleted)", 4: "4c", 5: "5d", 9: "(deleted)", 12: "12c", 20: "(deleted)",
+		}))
+	})
+
+	It("should drop tombstones", func() {
+		r, err := mapReader(map[uint64]string{5: "5d"}, 3, 9, 20)
+		Expect(err).NotTo(HaveOccurred())
+		readers = append(readers, r)
+
+		Expect(compact(&sntable.CompactOptions{DropTombstones: true})).To(Equal(map[uint64]string{
+			1: "1a", 2: "2b", 4: "4c", 5: "5d", 12: "12c",
+		}))
+	})
+
+	It("should filter entries", func() {
+		Expect(compact(&sntable.CompactOptions{
+			WriterOptions: sntable.WriterOptions{Compression: sntable.NoCompression},
+			Filter: func(key uint64, value []byte) ([]byte, bool) {
+				if key%3 == 0 {
+					return nil, false
+				}
+				return append([]byte("x"), value...), true
+			},
+		})).To(Equal(map[uint64]string{
+			1: "x1a", 2: "x2b", 4: "x4c", 5: "x5a",
+		}))
+	})
+
+	It("should compact empty sets", func() {
+		Expect(compact(&sntable.CompactOptions{
+			Filter: func(_ uint64, _ []byte) ([]byte, bool) { return nil, false },
+		})).To(BeEmpty())
+	})
+})
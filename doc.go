@@ -6,20 +6,41 @@ Data Structure Documentation
 
 Store
 
-A store contains a series of data blocks followed by an index and
-a store footer.
+A store contains a series of data blocks followed by optional meta
+blocks, a metaindex, a block index and a store footer.
 
     Store layout:
-    +---------+---------+---------+-------------+--------------+
-    | block 1 |   ...   | block n | block index | store footer |
-    +---------+---------+---------+-------------+--------------+
+    +---------+---------+---------+--------------+---------+--------------+-----------+-------------+--------------+
+    | block 1 |   ...   | block n | meta block 1 |   ...   | meta block m | metaindex | block index | store footer |
+    +---------+---------+---------+--------------+---------+--------------+-----------+-------------+--------------+
 
     Block index:
     +----------------------------+--------------------+----------------------------------+--------------------------+--------+
     | last cell block 1 (varint) |  offset 2 (varint) | last cell block 2 (varint,delta) |  offset 2 (varint,delta) |   ...  |
     +----------------------------+--------------------+----------------------------------+--------------------------+--------+
 
+    Metaindex:
+    +---------------------+----------------+-------------------+-------------------+--------+
+    | name len 1 (varint) | name 1 (bytes) | offset 1 (varint) | length 1 (varint) |   ...  |
+    +---------------------+----------------+-------------------+-------------------+--------+
+
     Store footer:
+    +----------------------------+------------------------+-------------------------+--------------------------+-----------------+
+    | metaindex offset (8 bytes) | index offset (8 bytes) | feature flags (4 bytes) | format version (4 bytes) | magic (8 bytes) |
+    +----------------------------+------------------------+-------------------------+--------------------------+-----------------+
+
+Meta blocks are stored in the same way as data blocks and are referenced
+by name from the metaindex. Readers ignore meta blocks they don't know.
+Feature flags, on the other hand, indicate format extensions which are
+required to read the table. Readers reject tables with unknown feature
+flags or format versions. The following feature flags are defined:
+
+    0x1  entry kinds, entries carry a put/delete kind (see Section)
+
+Legacy (version 1) stores have neither meta blocks nor a metaindex and
+use a shorter footer, with a different magic byte sequence.
+
+    Legacy store footer:
     +------------------------+------------------+
     | index offset (8 bytes) |  magic (8 bytes) |
     +------------------------+------------------+
@@ -27,12 +48,15 @@ a store footer.
 Block
 
 A block comprises of a series of sections, followed by a section
-index and a single-byte compression type indicator.
+index, a CRC32C checksum and a single-byte compression type indicator.
+The checksum covers the (compressed) block data and the compression type.
+Blocks with a checksum have the most significant bit of the compression
+type indicator set, legacy blocks are stored without a checksum.
 
     Block layout:
-    +-----------+---------+-----------+---------------+---------------------------+
-    | section 1 |   ...   | section n | section index | compression type (1-byte) |
-    +-----------+---------+-----------+---------------+---------------------------+
+    +-----------+---------+-----------+---------------+--------------------+---------------------------+
+    | section 1 |   ...   | section n | section index | checksum (4 bytes) | compression type (1-byte) |
+    +-----------+---------+-----------+---------------+--------------------+---------------------------+
 
     Section index:
     +----------------------------+-------+----------------------------+-------------------------------+
@@ -47,5 +71,38 @@ are delta encoded.
     +----------------+----------------------+------------------+----------------------+----------------------+------------------+-------+
     | key 1 (varint) | value len 1 (varint) | value 1 (varlen) | key 2 (varint,delta) | value len 2 (varint) | value 2 (varlen) |  ...  |
     +----------------+----------------------+------------------+----------------------+----------------------+------------------+-------+
+
+Tables with the "entry kinds" feature flag (0x1) store the kind of each
+entry in the lowest bit of the value length, i.e. the value length is
+encoded as (len << 1 | kind), where kind is 0 for regular entries (puts)
+and 1 for deletion markers (tombstones). Tombstones have no value.
+
+Filter
+
+Tables may contain an optional "filter.bloom" meta block with a bloom
+filter for each data block. Filters are indexed in the same way as sections
+within a block.
+
+    Filter block layout:
+    +----------+-------+----------+--------------+
+    | filter 1 |  ...  | filter n | filter index |
+    +----------+-------+----------+--------------+
+
+    Filter:
+    +-----------------+---------------------------+
+    | bitset (varlen) | number of probes (1 byte) |
+    +-----------------+---------------------------+
+
+Properties
+
+Tables contain a "properties" meta block with metadata about the table.
+Properties are stored as a series of name/value pairs, sorted by name.
+Values of built-in properties, which have a "sntable." name prefix, are
+varint encoded.
+
+    Properties block layout:
+    +---------------------+----------------+----------------------+-----------------+--------+
+    | name len 1 (varint) | name 1 (bytes) | value len 1 (varint) | value 1 (bytes) |   ...  |
+    +---------------------+----------------+----------------------+-----------------+--------+
 */
 package sntable
@@ -0,0 +1,171 @@
+package sntable
+
+import (
+	"encoding/binary"
+)
+
+// filterBloomName is the name of the bloom filter meta block.
+const filterBloomName = "filter.bloom"
+
+// FilterStats contain filter usage statistics.
+type FilterStats struct {
+	// Hits is the number of lookups where the filter
+	// prevented a block read.
+	Hits uint64
+	// FalsePositives is the number of lookups where the filter
+	// failed to prevent the unnecessary read of a block.
+	FalsePositives uint64
+}
+
+// bloomHash mixes the bits of a key.
+func bloomHash(key uint64) uint64 {
+	key ^= key >> 33
+	key *= 0xff51afd7ed558ccd
+	key ^= key >> 33
+	key *= 0xc4ceb9fe1a85ec53
+	key ^= key >> 33
+	return key
+}
+
+// appendBloom appends a bloom filter for keys to dst. Each filter is
+// a bitset followed by a single byte, holding the number of probes.
+func appendBloom(dst []byte, keys []uint64, bitsPerKey int) []byte {
+	k := bitsPerKey * 69 / 100 // 0.69 =~ ln(2)
+	if k < 1 {
+		k = 1
+	} else if k > 30 {
+		k = 30
+	}
+
+	nbits := len(keys) * bitsPerKey
+	if nbits < 64 {
+		nbits = 64
+	}
+	nbytes := (nbits + 7) / 8
+	nbits = nbytes * 8
+
+	off := len(dst)
+	for i := 0; i < nbytes; i++ {
+		dst = append(dst, 0)
+	}
+	bits := dst[off:]
+
+	for _, key := range keys {
+		h := bloomHash(key)
+		h1, h2 := uint32(h), uint32(h>>32)
+		for i := 0; i < k; i++ {
+			pos := (h1 + uint32(i)*h2) % uint32(nbits)
+			bits[pos/8] |= 1 << (pos % 8)
+		}
+	}
+	return append(dst, byte(k))
+}
+
+// bloomMayContain returns true if the key may be contained in the filter.
+func bloomMayContain(filter []byte, key uint64) bool {
+	if len(filter) < 2 {
+		return true
+	}
+
+	nbytes := len(filter) - 1
+	nbits := uint32(nbytes * 8)
+	k := int(filter[nbytes])
+	if k > 30 { // reserved for future encodings
+		return true
+	}
+
+	h := bloomHash(key)
+	h1, h2 := uint32(h), uint32(h>>32)
+	for i := 0; i < k; i++ {
+		pos := (h1 + uint32(i)*h2) % nbits
+		if filter[pos/8]&(1<<(pos%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// --------------------------------------------------------------------
+
+// filterWriter builds a filter block with a bloom filter for each data block.
+type filterWriter struct {
+	bitsPerKey int
+
+	keys []uint64 // keys of the current block
+	buf  []byte   // filter block buffer
+	offs []int    // filter offsets
+}
+
+func (w *filterWriter) Add(key uint64) {
+	w.keys = append(w.keys, key)
+}
+
+// Flush appends a filter for the current block.
+func (w *filterWriter) Flush() {
+	w.offs = append(w.offs, len(w.buf))
+	w.buf = appendBloom(w.buf, w.keys, w.bitsPerKey)
+	w.keys = w.keys[:0]
+}
+
+// Finish appends the filter offsets and returns the filter block.
+func (w *filterWriter) Finish() []byte {
+	var tmp [4]byte
+	for _, o := range w.offs {
+		if o > 0 {
+			binary.LittleEndian.PutUint32(tmp[:], uint32(o))
+			w.buf = append(w.buf, tmp[:]...)
+		}
+	}
+	binary.LittleEndian.PutUint32(tmp[:], uint32(len(w.offs)))
+	return append(w.buf, tmp[:]...)
+}
+
+// filterReader reads a filter block.
+type filterReader struct {
+	block []byte
+	fcnt  int // the filter count
+}
+
+func newFilterReader(block []byte) (*filterReader, error) {
+	if len(block) < 4 {
+		return nil, errBadFilter
+	}
+
+	fcnt := int(binary.LittleEndian.Uint32(block[len(block)-4:]))
+	if fcnt < 1 || fcnt > len(block)/4 {
+		return nil, errBadFilter
+	}
+
+	r := &filterReader{block: block, fcnt: fcnt}
+	for i, last := 1, 0; i <= fcnt; i++ {
+		off := r.filterOffset(i)
+		if off < last || off > len(block)-fcnt*4 {
+			return nil, errBadFilter
+		}
+		last = off
+	}
+	return r, nil
+}
+
+// MayContain returns true if the block at bpos may contain key.
+func (r *filterReader) MayContain(bpos int, key uint64) bool {
+	if bpos < 0 || bpos >= r.fcnt {
+		return true
+	}
+
+	min := r.filterOffset(bpos)
+	max := r.filterOffset(bpos + 1)
+	return bloomMayContain(r.block[min:max], key)
+}
+
+// The starting offset of the filter within the block.
+func (r *filterReader) filterOffset(fpos int) int {
+	if fpos < 1 {
+		return 0
+	} else if fpos >= r.fcnt {
+		return len(r.block) - r.fcnt*4
+	} else {
+		nn := len(r.block) - r.fcnt*4 + (fpos-1)*4
+		return int(binary.LittleEndian.Uint32(r.block[nn:]))
+	}
+}
@@ -0,0 +1,167 @@
+package sntable
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+)
+
+var (
+	magicV1 = []byte{71, 39, 134, 190, 31, 122, 101, 219}
+	magicV2 = []byte{94, 145, 43, 196, 13, 115, 168, 246}
+)
+
+const (
+	footerLenV1 = 16
+	footerLenV2 = 32
+
+	// formatVersion is the most recent format version.
+	formatVersion = 2
+
+	// featureEntryKinds indicates that the value length of each
+	// entry is shifted left by one bit and that the lowest bit
+	// encodes the entry kind.
+	featureEntryKinds = 1 << 0
+
+	// knownFeatures is a mask of all feature flags supported
+	// by this implementation.
+	knownFeatures = featureEntryKinds
+)
+
+// Footer contains the decoded table footer.
+type Footer struct {
+	Version         uint32 // the format version
+	Features        uint32 // feature flags
+	MetaIndexOffset int64  // the metaindex offset
+	IndexOffset     int64  // the block index offset
+	Offset          int64  // the footer offset
+}
+
+func readFooter(r io.ReaderAt, size int64) (*Footer, error) {
+	if size < footerLenV1 {
+		return nil, errBadMagic
+	}
+
+	tmp := make([]byte, footerLenV2)
+	if size < footerLenV2 {
+		tmp = tmp[:footerLenV1]
+	}
+	if _, err := r.ReadAt(tmp, size-int64(len(tmp))); err != nil {
+		return nil, err
+	}
+
+	switch magic := tmp[len(tmp)-8:]; {
+	case bytes.Equal(magic, magicV1):
+		ft := &Footer{Version: 1, Offset: size - footerLenV1}
+		ft.IndexOffset = int64(binary.LittleEndian.Uint64(tmp[len(tmp)-16:]))
+		ft.MetaIndexOffset = ft.IndexOffset
+		return ft, ft.validate()
+	case bytes.Equal(magic, magicV2) && len(tmp) == footerLenV2:
+		ft := &Footer{Offset: size - footerLenV2}
+		ft.MetaIndexOffset = int64(binary.LittleEndian.Uint64(tmp[0:]))
+		ft.IndexOffset = int64(binary.LittleEndian.Uint64(tmp[8:]))
+		ft.Features = binary.LittleEndian.Uint32(tmp[16:])
+		ft.Version = binary.LittleEndian.Uint32(tmp[20:])
+		return ft, ft.validate()
+	}
+	return nil, errBadMagic
+}
+
+func (f *Footer) validate() error {
+	if f.Version < 1 || f.Version > formatVersion {
+		return fmt.Errorf("sntable: unsupported format version %d", f.Version)
+	}
+	if x := f.Features &^ knownFeatures; x != 0 {
+		return fmt.Errorf("sntable: unsupported feature flags %#x", x)
+	}
+	if f.MetaIndexOffset < 0 || f.MetaIndexOffset > f.IndexOffset || f.IndexOffset > f.Offset {
+		return errBadFooter
+	}
+	return nil
+}
+
+func (f *Footer) appendTo(dst []byte) []byte {
+	var tmp [footerLenV2]byte
+	binary.LittleEndian.PutUint64(tmp[0:], uint64(f.MetaIndexOffset))
+	binary.LittleEndian.PutUint64(tmp[8:], uint64(f.IndexOffset))
+	binary.LittleEndian.PutUint32(tmp[16:], f.Features)
+	binary.LittleEndian.PutUint32(tmp[20:], f.Version)
+	copy(tmp[24:], magicV2)
+	return append(dst, tmp[:]...)
+}
+
+// --------------------------------------------------------------------
+
+// blockHandle points to a block within the table.
+type blockHandle struct {
+	Offset int64 // the block offset
+	Length int64 // the block length, including the trailer
+}
+
+// metaIndex maps meta block names to their handles.
+type metaIndex map[string]blockHandle
+
+func readMetaIndex(r io.ReaderAt, ft *Footer) (metaIndex, error) {
+	mi := make(metaIndex)
+	if ft.MetaIndexOffset == ft.IndexOffset {
+		return mi, nil
+	}
+
+	buf := make([]byte, int(ft.IndexOffset-ft.MetaIndexOffset))
+	if _, err := r.ReadAt(buf, ft.MetaIndexOffset); err != nil {
+		return nil, err
+	}
+
+	for len(buf) != 0 {
+		nlen, n := binary.Uvarint(buf)
+		if n <= 0 || uint64(len(buf)-n) < nlen {
+			return nil, errBadMetaIndex
+		}
+		buf = buf[n:]
+		name := string(buf[:nlen])
+		buf = buf[nlen:]
+
+		off, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, errBadMetaIndex
+		}
+		buf = buf[n:]
+
+		sz, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, errBadMetaIndex
+		}
+		buf = buf[n:]
+
+		if max := uint64(ft.MetaIndexOffset); off > max || sz > max-off {
+			return nil, errBadMetaIndex
+		}
+		mi[name] = blockHandle{Offset: int64(off), Length: int64(sz)}
+	}
+	return mi, nil
+}
+
+func (mi metaIndex) appendTo(dst []byte) []byte {
+	names := make([]string, 0, len(mi))
+	for name := range mi {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		h := mi[name]
+		dst = appendUvarint(dst, uint64(len(name)))
+		dst = append(dst, name...)
+		dst = appendUvarint(dst, uint64(h.Offset))
+		dst = appendUvarint(dst, uint64(h.Length))
+	}
+	return dst
+}
+
+func appendUvarint(dst []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(dst, tmp[:n]...)
+}
@@ -0,0 +1,162 @@
+package sntable
+
+import "container/heap"
+
+// DuplicatePolicy determines how duplicate keys across multiple
+// sources are handled.
+type DuplicatePolicy int
+
+// Supported duplicate policies.
+const (
+	// FirstWins yields only the entry of the first source (by order).
+	FirstWins DuplicatePolicy = iota
+	// LastWins yields only the entry of the last source (by order).
+	LastWins
+	// YieldAll yields all entries, ordered by source.
+	YieldAll
+)
+
+// MergingIterator merges multiple iterators and yields their entries in
+// ascending key order.
+type MergingIterator struct {
+	iters  []*Iterator
+	policy DuplicatePolicy
+
+	heap    mergeHeap
+	pending []int // sources to advance on next
+	started bool
+
+	cur int // the current source
+	err error
+}
+
+// NewMergingIterator creates a new merging iterator. The given iterators
+// must be positioned before their first entries (i.e. as returned by
+// Reader.Seek or Reader.NewIterator) and must not be used directly once
+// passed to the merging iterator.
+func NewMergingIterator(policy DuplicatePolicy, iters ...*Iterator) *MergingIterator {
+	m := &MergingIterator{
+		iters:  iters,
+		policy: policy,
+		cur:    -1,
+	}
+	m.heap.iters = iters
+	return m
+}
+
+// MergeReaders creates a merging iterator across multiple readers. The
+// optional iterator options are applied to each of the readers.
+func MergeReaders(policy DuplicatePolicy, o *IteratorOptions, readers ...*Reader) (*MergingIterator, error) {
+	iters := make([]*Iterator, 0, len(readers))
+	for _, r := range readers {
+		it, err := r.NewIterator(o)
+		if err != nil {
+			for _, it := range iters {
+				it.Release()
+			}
+			return nil, err
+		}
+		iters = append(iters, it)
+	}
+	return NewMergingIterator(policy, iters...), nil
+}
+
+// Key returns the key of the current entry.
+func (m *MergingIterator) Key() uint64 { return m.iters[m.cur].Key() }
+
+// Kind returns the kind of the current entry.
+func (m *MergingIterator) Kind() Kind { return m.iters[m.cur].Kind() }
+
+// Value returns the value of the current entry. Please note that values
+// are temporary buffers and must be copied if used beyond the next cursor move.
+func (m *MergingIterator) Value() []byte { return m.iters[m.cur].Value() }
+
+// Source returns the index of the source iterator of the current entry.
+func (m *MergingIterator) Source() int { return m.cur }
+
+// Next advances the cursor to the next entry and returns true if successful.
+func (m *MergingIterator) Next() bool {
+	if m.err != nil {
+		return false
+	}
+
+	if !m.started {
+		m.started = true
+		for i := range m.iters {
+			m.pending = append(m.pending, i)
+		}
+	}
+
+	// advance pending sources
+	for _, i := range m.pending {
+		if m.iters[i].Next() {
+			heap.Push(&m.heap, i)
+		} else if err := m.iters[i].Err(); err != nil {
+			m.err = err
+			return false
+		}
+	}
+	m.pending = m.pending[:0]
+
+	if m.heap.Len() == 0 {
+		m.cur = -1
+		return false
+	}
+
+	m.cur = heap.Pop(&m.heap).(int)
+	m.pending = append(m.pending, m.cur)
+	if m.policy == YieldAll {
+		return true
+	}
+
+	// skip duplicates
+	key := m.iters[m.cur].Key()
+	for m.heap.Len() != 0 && m.iters[m.heap.items[0]].Key() == key {
+		i := heap.Pop(&m.heap).(int)
+		m.pending = append(m.pending, i)
+		if m.policy == LastWins {
+			m.cur = i
+		}
+	}
+	return true
+}
+
+// Err exposes iterator errors, if any.
+func (m *MergingIterator) Err() error {
+	return m.err
+}
+
+// Release releases the iterator and all its sources. The iterator must not
+// be used after this method is called.
+func (m *MergingIterator) Release() {
+	for _, it := range m.iters {
+		it.Release()
+	}
+	m.err = errReleased
+}
+
+// --------------------------------------------------------------------
+
+// mergeHeap is a min-heap of source positions, ordered by the
+// current key of the source and the source position.
+type mergeHeap struct {
+	iters []*Iterator
+	items []int
+}
+
+func (h *mergeHeap) Len() int { return len(h.items) }
+func (h *mergeHeap) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if ka, kb := h.iters[a].Key(), h.iters[b].Key(); ka != kb {
+		return ka < kb
+	}
+	return a < b
+}
+func (h *mergeHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *mergeHeap) Push(x interface{}) { h.items = append(h.items, x.(int)) }
+func (h *mergeHeap) Pop() interface{} {
+	n := len(h.items) - 1
+	x := h.items[n]
+	h.items = h.items[:n]
+	return x
+}
@@ -0,0 +1,111 @@
+package sntable_test
+
+import (
+	"fmt"
+
+	"github.com/bsm/sntable"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MergingIterator", func() {
+	var readers []*sntable.Reader
+
+	open := func(vals map[uint64]string) *sntable.Reader {
+		r, err := mapReader(vals)
+		Expect(err).NotTo(HaveOccurred())
+		return r
+	}
+
+	drain := func(policy sntable.DuplicatePolicy, o *sntable.IteratorOptions) []string {
+		iter, err := sntable.MergeReaders(policy, o, readers...)
+		Expect(err).NotTo(HaveOccurred())
+		defer iter.Release()
+
+		var res []string
+		for iter.Next() {
+			res = append(res, string(iter.Value())+"@"+string(rune('0'+iter.Source())))
+			Expect(string(iter.Value())).To(HaveSuffix(string(rune('a' + iter.Source()))))
+		}
+		Expect(iter.Err()).NotTo(HaveOccurred())
+		return res
+	}
+
+	BeforeEach(func() {
+		readers = []*sntable.Reader{
+			open(map[uint64]string{1: "1a", 3: "3a", 5: "5a", 9: "9a"}),
+			open(map[uint64]string{}),
+			open(map[uint64]string{2: "2c", 3: "3c", 9: "9c", 12: "12c"}),
+			open(map[uint64]string{3: "3d", 4: "4d", 12: "12d"}),
+		}
+	})
+
+	AfterEach(func() {
+		for _, r := range readers {
+			Expect(r.Close()).To(Succeed())
+		}
+	})
+
+	It("should merge (first wins)", func() {
+		Expect(drain(sntable.FirstWins, nil)).To(Equal([]string{
+			"1a@0", "2c@2", "3a@0", "4d@3", "5a@0", "9a@0", "12c@2",
+		}))
+	})
+
+	It("should merge (last wins)", func() {
+		Expect(drain(sntable.LastWins, nil)).To(Equal([]string{
+			"1a@0", "2c@2", "3d@3", "4d@3", "5a@0", "9c@2", "12d@3",
+		}))
+	})
+
+	It("should merge (yield all)", func() {
+		Expect(drain(sntable.YieldAll, nil)).To(Equal([]string{
+			"1a@0", "2c@2", "3a@0", "3c@2", "3d@3", "4d@3", "5a@0", "9a@0", "9c@2", "12c@2", "12d@3",
+		}))
+	})
+
+	It("should apply iterator options", func() {
+		Expect(drain(sntable.LastWins, &sntable.IteratorOptions{
+			LowerBound: sntable.Exclusive(2),
+			UpperBound: sntable.Inclusive(9),
+		})).To(Equal([]string{
+			"3d@3", "4d@3", "5a@0", "9c@2",
+		}))
+	})
+
+	It("should shadow deleted keys", func() {
+		r, err := mapReader(map[uint64]string{4: "4e"}, 3, 9)
+		Expect(err).NotTo(HaveOccurred())
+		readers = append(readers, r)
+
+		iter, err := sntable.MergeReaders(sntable.LastWins, nil, readers...)
+		Expect(err).NotTo(HaveOccurred())
+		defer iter.Release()
+
+		var res []string
+		for iter.Next() {
+			res = append(res, fmt.Sprintf("%d:%s@%d", iter.Key(), iter.Kind(), iter.Source()))
+		}
+		Expect(iter.Err()).NotTo(HaveOccurred())
+		Expect(res).To(Equal([]string{
+			"1:put@0", "2:put@2", "3:delete@4", "4:put@4", "5:put@0", "9:delete@4", "12:put@3",
+		}))
+	})
+
+	It("should merge iterators", func() {
+		it1, err := readers[0].Seek(4)
+		Expect(err).NotTo(HaveOccurred())
+		it2, err := readers[3].Seek(0)
+		Expect(err).NotTo(HaveOccurred())
+
+		iter := sntable.NewMergingIterator(sntable.FirstWins, it1, it2)
+		defer iter.Release()
+
+		var keys []uint64
+		for iter.Next() {
+			keys = append(keys, iter.Key())
+		}
+		Expect(iter.Err()).NotTo(HaveOccurred())
+		Expect(keys).To(Equal([]uint64{3, 4, 5, 9, 12}))
+	})
+})
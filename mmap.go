@@ -0,0 +1,85 @@
+package sntable
+
+import (
+	"io"
+	"sync"
+)
+
+// mapping is a read-only memory mapping of a table file. Blocks may
+// reference the mapped memory directly, which is why the mapping keeps
+// track of references and is only unmapped once all of them are released.
+type mapping struct {
+	data  []byte
+	unmap func([]byte) error
+
+	mu     sync.Mutex
+	refs   int
+	closed bool
+}
+
+// ReadAt implements io.ReaderAt.
+func (m *mapping) ReadAt(p []byte, off int64) (int, error) {
+	if !m.acquire() {
+		return 0, errClosed
+	}
+	defer m.release()
+
+	if off < 0 || off > int64(len(m.data)) {
+		return 0, io.EOF
+	}
+
+	n := copy(p, m.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// acquire acquires a reference, returns false if the mapping is closed.
+func (m *mapping) acquire() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return false
+	}
+	m.refs++
+	return true
+}
+
+// release releases a reference.
+func (m *mapping) release() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.refs--; m.refs == 0 && m.closed {
+		_ = m.free()
+	}
+}
+
+// close closes the mapping. The data is unmapped immediately if there are no
+// outstanding references, or as soon as the last reference is released.
+func (m *mapping) close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return errClosed
+	}
+	m.closed = true
+
+	if m.refs == 0 {
+		return m.free()
+	}
+	return nil
+}
+
+func (m *mapping) free() error {
+	data := m.data
+	m.data = nil
+
+	if data == nil || m.unmap == nil {
+		return nil
+	}
+	return m.unmap(data)
+}
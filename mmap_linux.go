@@ -0,0 +1,50 @@
+//go:build linux
+// +build linux
+
+package sntable
+
+import (
+	"os"
+	"syscall"
+)
+
+// OpenMmap opens a table file and maps it into memory. Unlike readers
+// returned by NewReader, uncompressed blocks are not copied but
+// reference the mapped memory directly, so are the values returned by
+// iterators.
+//
+// Please note that the reader must be closed after use. Once closed, the
+// file is unmapped as soon as all outstanding iterators and blocks are
+// released.
+func OpenMmap(path string) (*Reader, error) {
+	return OpenMmapWithOptions(path, nil)
+}
+
+// OpenMmapWithOptions opens a memory-mapped table file with custom options.
+func OpenMmapWithOptions(path string, o *ReaderOptions) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	mm := &mapping{unmap: syscall.Munmap}
+	if size := fi.Size(); size != 0 {
+		if mm.data, err = syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED); err != nil {
+			return nil, err
+		}
+	}
+
+	r, err := NewReaderWithOptions(mm, fi.Size(), o)
+	if err != nil {
+		_ = mm.close()
+		return nil, err
+	}
+	r.mm = mm
+	return r, nil
+}
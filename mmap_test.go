@@ -0,0 +1,87 @@
+//go:build linux
+// +build linux
+
+package sntable_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/bsm/sntable"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OpenMmap", func() {
+	var subject *sntable.Reader
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "sntable-test")
+		Expect(err).NotTo(HaveOccurred())
+
+		buf := new(bytes.Buffer)
+		Expect(seedTable(buf, 100)).To(Succeed())
+
+		fname := filepath.Join(dir, "test.snt")
+		Expect(ioutil.WriteFile(fname, buf.Bytes(), 0644)).To(Succeed())
+
+		subject, err = sntable.OpenMmap(fname)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_ = subject.Close()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should open", func() {
+		Expect(subject.NumBlocks()).To(Equal(4))
+		Expect(subject.Get(200)).To(HaveSuffix("0200"))
+		Expect(subject.Get(396)).To(HaveSuffix("0396"))
+
+		_, err := subject.Get(201)
+		Expect(err).To(MatchError(sntable.ErrNotFound))
+	})
+
+	It("should iterate", func() {
+		iter, err := subject.Seek(0)
+		Expect(err).NotTo(HaveOccurred())
+		defer iter.Release()
+
+		var n int
+		for iter.Next() {
+			n++
+		}
+		Expect(iter.Err()).NotTo(HaveOccurred())
+		Expect(n).To(Equal(100))
+	})
+
+	It("should prevent use after close", func() {
+		iter, err := subject.Seek(200)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(subject.Close()).To(Succeed())
+		Expect(subject.Close()).To(MatchError(`sntable: is closed`))
+
+		_, err = subject.Get(200)
+		Expect(err).To(MatchError(`sntable: is closed`))
+
+		// outstanding iterators can still access the current block
+		Expect(iter.Next()).To(BeTrue())
+		Expect(iter.Key()).To(Equal(uint64(200)))
+		Expect(iter.Value()).To(HaveSuffix("0200"))
+		iter.Release()
+	})
+
+	It("should reject bad files", func() {
+		fname := filepath.Join(dir, "bad.snt")
+		Expect(ioutil.WriteFile(fname, []byte("not a table"), 0644)).To(Succeed())
+
+		_, err := sntable.OpenMmap(fname)
+		Expect(err).To(MatchError(`sntable: bad magic byte sequence`))
+	})
+})
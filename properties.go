@@ -0,0 +1,158 @@
+package sntable
+
+import (
+	"encoding/binary"
+	"sort"
+	"strings"
+	"time"
+)
+
+// propertiesName is the name of the properties meta block.
+const propertiesName = "properties"
+
+// reservedPropertyPrefix is the name prefix of built-in properties.
+const reservedPropertyPrefix = "sntable."
+
+// Properties contain table metadata.
+type Properties struct {
+	NumEntries   uint64 // the number of entries, including deletions
+	NumDeletions uint64 // the number of deletions
+	NumBlocks    uint64 // the number of data blocks
+	MinKey       uint64 // the minimum key
+	MaxKey       uint64 // the maximum key
+
+	RawKeySize   uint64 // the total size of all (encoded) keys
+	RawValueSize uint64 // the total size of all values
+	RawDataSize  uint64 // the total size of all data blocks, uncompressed
+	DataSize     uint64 // the total size of all data blocks, as stored
+
+	Compression          Compression // the compression codec
+	BlockSize            int         // the block size
+	BlockRestartInterval int         // the block restart interval
+	FilterBitsPerKey     int         // the number of filter bits per key
+
+	CreatedAt      time.Time         // the creation time
+	UserProperties map[string]string // user-supplied properties
+}
+
+// builtin returns the built-in properties as name/value pairs.
+func (p *Properties) builtin() map[string]uint64 {
+	return map[string]uint64{
+		"sntable.num.entries":            p.NumEntries,
+		"sntable.num.deletions":          p.NumDeletions,
+		"sntable.num.blocks":             p.NumBlocks,
+		"sntable.min.key":                p.MinKey,
+		"sntable.max.key":                p.MaxKey,
+		"sntable.raw.key.size":           p.RawKeySize,
+		"sntable.raw.value.size":         p.RawValueSize,
+		"sntable.raw.data.size":          p.RawDataSize,
+		"sntable.data.size":              p.DataSize,
+		"sntable.compression":            uint64(p.Compression),
+		"sntable.block.size":             uint64(p.BlockSize),
+		"sntable.block.restart.interval": uint64(p.BlockRestartInterval),
+		"sntable.filter.bits.per.key":    uint64(p.FilterBitsPerKey),
+		"sntable.created.at":             uint64(p.CreatedAt.UnixNano()),
+	}
+}
+
+func (p *Properties) set(name string, v uint64) {
+	switch name {
+	case "sntable.num.entries":
+		p.NumEntries = v
+	case "sntable.num.deletions":
+		p.NumDeletions = v
+	case "sntable.num.blocks":
+		p.NumBlocks = v
+	case "sntable.min.key":
+		p.MinKey = v
+	case "sntable.max.key":
+		p.MaxKey = v
+	case "sntable.raw.key.size":
+		p.RawKeySize = v
+	case "sntable.raw.value.size":
+		p.RawValueSize = v
+	case "sntable.raw.data.size":
+		p.RawDataSize = v
+	case "sntable.data.size":
+		p.DataSize = v
+	case "sntable.compression":
+		p.Compression = Compression(v)
+	case "sntable.block.size":
+		p.BlockSize = int(v)
+	case "sntable.block.restart.interval":
+		p.BlockRestartInterval = int(v)
+	case "sntable.filter.bits.per.key":
+		p.FilterBitsPerKey = int(v)
+	case "sntable.created.at":
+		p.CreatedAt = time.Unix(0, int64(v))
+	}
+}
+
+// appendTo encodes properties as a series of name/value pairs, sorted by
+// name. Values of built-in properties are varint encoded.
+func (p *Properties) appendTo(dst []byte) []byte {
+	pairs := make(map[string][]byte, len(p.UserProperties)+16)
+	for name, v := range p.builtin() {
+		pairs[name] = appendUvarint(nil, v)
+	}
+	for name, v := range p.UserProperties {
+		if !strings.HasPrefix(name, reservedPropertyPrefix) {
+			pairs[name] = []byte(v)
+		}
+	}
+
+	names := make([]string, 0, len(pairs))
+	for name := range pairs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		v := pairs[name]
+		dst = appendUvarint(dst, uint64(len(name)))
+		dst = append(dst, name...)
+		dst = appendUvarint(dst, uint64(len(v)))
+		dst = append(dst, v...)
+	}
+	return dst
+}
+
+func parseProperties(buf []byte) (*Properties, error) {
+	p := new(Properties)
+	for len(buf) != 0 {
+		name, rest, ok := readLenPrefixed(buf)
+		if !ok {
+			return nil, errBadProperties
+		}
+		val, rest, ok := readLenPrefixed(rest)
+		if !ok {
+			return nil, errBadProperties
+		}
+		buf = rest
+
+		if !strings.HasPrefix(string(name), reservedPropertyPrefix) {
+			if p.UserProperties == nil {
+				p.UserProperties = make(map[string]string)
+			}
+			p.UserProperties[string(name)] = string(val)
+			continue
+		}
+
+		v, n := binary.Uvarint(val)
+		if n <= 0 {
+			return nil, errBadProperties
+		}
+		p.set(string(name), v)
+	}
+	return p, nil
+}
+
+// readLenPrefixed reads a varint length-prefixed byte slice.
+func readLenPrefixed(buf []byte) ([]byte, []byte, bool) {
+	sz, n := binary.Uvarint(buf)
+	if n <= 0 || uint64(len(buf)-n) < sz {
+		return nil, nil, false
+	}
+	buf = buf[n:]
+	return buf[:sz], buf[sz:], true
+}
@@ -0,0 +1,62 @@
+package sntable_test
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/bsm/sntable"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Properties", func() {
+	It("should write and read properties", func() {
+		buf := new(bytes.Buffer)
+		tw := sntable.NewWriter(buf, &sntable.WriterOptions{
+			BlockSize:        1024,
+			FilterBitsPerKey: 10,
+			UserProperties:   map[string]string{"source": "test", "sntable.num.entries": "bad"},
+		})
+		val := bytes.Repeat([]byte("testdata"), 16)
+		for key := uint64(1000); key < 2000; key += 2 {
+			Expect(tw.Append(key, val)).To(Succeed())
+		}
+		Expect(tw.Close()).To(Succeed())
+
+		tr, err := sntable.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		Expect(err).NotTo(HaveOccurred())
+
+		props := tr.Properties()
+		Expect(props).NotTo(BeNil())
+		Expect(props.CreatedAt).To(BeTemporally("~", time.Now(), time.Minute))
+		Expect(props.DataSize).To(BeNumerically("~", 5100, 200))
+		Expect(props.RawDataSize).To(BeNumerically("~", 66000, 200))
+
+		props.CreatedAt = time.Time{}
+		props.DataSize = 0
+		props.RawDataSize = 0
+		Expect(props).To(Equal(&sntable.Properties{
+			NumEntries:           500,
+			NumBlocks:            72,
+			MinKey:               1000,
+			MaxKey:               1998,
+			RawKeySize:           572,
+			RawValueSize:         64000,
+			Compression:          sntable.SnappyCompression,
+			BlockSize:            1024,
+			BlockRestartInterval: 16,
+			FilterBitsPerKey:     10,
+			UserProperties:       map[string]string{"source": "test"},
+		}))
+	})
+
+	It("should read empty tables", func() {
+		buf := new(bytes.Buffer)
+		Expect(sntable.NewWriter(buf, nil).Close()).To(Succeed())
+
+		tr, err := sntable.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tr.Properties().NumEntries).To(BeZero())
+		Expect(tr.Properties().NumBlocks).To(BeZero())
+	})
+})
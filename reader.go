@@ -1,46 +1,140 @@
 package sntable
 
 import (
-	"bytes"
 	"encoding/binary"
 	"io"
+	"math"
 	"sort"
 	"sync"
+	"sync/atomic"
 
 	"github.com/golang/snappy"
 )
 
+// ReaderOptions define reader specific options.
+type ReaderOptions struct {
+	// SkipChecksums disables verification of block checksums. This
+	// saves a few CPU cycles on hot paths at the cost of not detecting
+	// data corruption.
+	// Default: false.
+	SkipChecksums bool
+
+	// BlockCache is an optional cache for decompressed blocks.
+	// Caches can be shared by multiple readers.
+	// Default: nil (disabled).
+	BlockCache *BlockCache
+}
+
+func (o *ReaderOptions) norm() *ReaderOptions {
+	var oo ReaderOptions
+	if o != nil {
+		oo = *o
+	}
+	return &oo
+}
+
+// IteratorOptions define iterator specific options.
+type IteratorOptions struct {
+	// LowerBound limits iteration to keys at or above the bound.
+	// Default: nil (unbounded).
+	LowerBound *Bound
+
+	// UpperBound limits iteration to keys at or below the bound.
+	// Default: nil (unbounded).
+	UpperBound *Bound
+}
+
+// Bound is a key limit.
+type Bound struct {
+	Key       uint64 // the key
+	Exclusive bool   // true to exclude the key itself
+}
+
+// Inclusive returns an inclusive bound.
+func Inclusive(key uint64) *Bound { return &Bound{Key: key} }
+
+// Exclusive returns an exclusive bound.
+func Exclusive(key uint64) *Bound { return &Bound{Key: key, Exclusive: true} }
+
+// limits converts the bounds into an inclusive range. It returns false if
+// the range is empty.
+func (o *IteratorOptions) limits() (lo, hi uint64, ok bool) {
+	lo, hi = 0, math.MaxUint64
+	if o == nil {
+		return lo, hi, true
+	}
+
+	if b := o.LowerBound; b != nil {
+		if lo = b.Key; b.Exclusive {
+			if lo == math.MaxUint64 {
+				return 0, 0, false
+			}
+			lo++
+		}
+	}
+	if b := o.UpperBound; b != nil {
+		if hi = b.Key; b.Exclusive {
+			if hi == 0 {
+				return 0, 0, false
+			}
+			hi--
+		}
+	}
+	return lo, hi, lo <= hi
+}
+
 // Reader instances can seek and iterate across data in tables.
 type Reader struct {
-	r io.ReaderAt
-
-	index     []blockInfo
+	stats FilterStats // must be 64-bit aligned
+
+	r  io.ReaderAt
+	o  *ReaderOptions
+	id uint64   // the table ID, for caching
+	mm *mapping // the memory mapping, if any
+
+	ft        *Footer
+	index     []indexEntry
+	meta      metaIndex
+	filter    *filterReader
+	props     *Properties
 	maxOffset int64
 }
 
-// NewReader opens a reader.
+// NewReader opens a reader with default options.
 func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
-	tmp := make([]byte, 16+binary.MaxVarintLen64)
+	return NewReaderWithOptions(r, size, nil)
+}
 
+// NewReaderWithOptions opens a reader with custom options.
+func NewReaderWithOptions(r io.ReaderAt, size int64, o *ReaderOptions) (*Reader, error) {
 	// read footer
-	footerOffset := size - 16
-	if _, err := r.ReadAt(tmp[:16], footerOffset); err != nil {
+	ft, err := readFooter(r, size)
+	if err != nil {
 		return nil, err
 	}
 
-	// parse footer
-	if !bytes.Equal(tmp[8:16], magic) {
-		return nil, errBadMagic
+	// read metaindex
+	meta, err := readMetaIndex(r, ft)
+	if err != nil {
+		return nil, err
+	}
+
+	// data blocks are followed by meta blocks
+	maxOffset := ft.MetaIndexOffset
+	for _, h := range meta {
+		if h.Offset < maxOffset {
+			maxOffset = h.Offset
+		}
 	}
-	indexOffset := int64(binary.LittleEndian.Uint64(tmp[:8]))
 
 	// read index
-	var index []blockInfo
-	var info blockInfo
+	var index []indexEntry
+	var info indexEntry
 
-	for pos := indexOffset; pos < footerOffset; {
+	tmp := make([]byte, 2*binary.MaxVarintLen64)
+	for pos := ft.IndexOffset; pos < ft.Offset; {
 		tmp = tmp[:2*binary.MaxVarintLen64]
-		if x := footerOffset - pos; x < int64(len(tmp)) {
+		if x := ft.Offset - pos; x < int64(len(tmp)) {
 			tmp = tmp[:int(x)]
 		}
 
@@ -60,12 +154,78 @@ func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
 		index = append(index, info)
 	}
 
-	return &Reader{
+	rd := &Reader{
 		r: r,
+		o: o.norm(),
 
+		ft:        ft,
 		index:     index, // block offsets
-		maxOffset: indexOffset,
-	}, nil
+		meta:      meta,
+		maxOffset: maxOffset,
+	}
+	if c := rd.o.BlockCache; c != nil {
+		rd.id = c.newID()
+	}
+
+	// read filter
+	if h, ok := meta[filterBloomName]; ok {
+		block, _, err := rd.readRawBlock(-1, h.Offset, h.Offset+h.Length)
+		if err != nil {
+			return nil, err
+		}
+		if rd.filter, err = newFilterReader(block); err != nil {
+			return nil, err
+		}
+	}
+
+	// read properties
+	if h, ok := meta[propertiesName]; ok {
+		block, _, err := rd.readRawBlock(-1, h.Offset, h.Offset+h.Length)
+		if err != nil {
+			return nil, err
+		}
+		defer releaseBuffer(block)
+
+		if rd.props, err = parseProperties(block); err != nil {
+			return nil, err
+		}
+	}
+
+	return rd, nil
+}
+
+// Close closes the reader. Readers opened via OpenMmap are unmapped
+// once all outstanding iterators and blocks are released.
+func (r *Reader) Close() error {
+	if r.mm != nil {
+		return r.mm.close()
+	}
+	return nil
+}
+
+// Properties returns the table properties. It returns nil for legacy
+// tables which were written without properties.
+func (r *Reader) Properties() *Properties {
+	return r.props
+}
+
+// Footer returns the table footer.
+func (r *Reader) Footer() Footer {
+	return *r.ft
+}
+
+// BlockInfo returns information about the n-th block.
+func (r *Reader) BlockInfo(bpos int) BlockInfo {
+	if bpos < 0 || bpos >= len(r.index) {
+		return BlockInfo{}
+	}
+
+	min, max := r.blockOffsets(bpos)
+	return BlockInfo{
+		MaxKey: r.index[bpos].MaxKey,
+		Offset: min,
+		Length: max - min,
+	}
 }
 
 // NumBlocks returns the number of stored blocks.
@@ -73,24 +233,51 @@ func (r *Reader) NumBlocks() int {
 	return len(r.index)
 }
 
+// FilterStats returns filter usage statistics.
+func (r *Reader) FilterStats() FilterStats {
+	return FilterStats{
+		Hits:           atomic.LoadUint64(&r.stats.Hits),
+		FalsePositives: atomic.LoadUint64(&r.stats.FalsePositives),
+	}
+}
+
 // Append retrieves a single value for a key. Unlike Get it doesn't
 // appends it to dst instead of allocating a new byte slice.
-// It may return an ErrNotFound error.
+// It may return an ErrNotFound or an ErrDeleted error.
 func (r *Reader) Append(dst []byte, key uint64) ([]byte, error) {
-	iter, err := r.Seek(key)
+	bpos := r.searchBlock(key)
+	if bpos >= len(r.index) {
+		return dst, ErrNotFound
+	}
+	if r.filter != nil && !r.filter.MayContain(bpos, key) {
+		atomic.AddUint64(&r.stats.Hits, 1)
+		return dst, ErrNotFound
+	}
+
+	b, err := r.GetBlock(bpos)
 	if err != nil {
 		return dst, err
 	}
+
+	s := b.SeekSection(key)
+	s.Seek(key)
+	iter := r.newIterator(b, s)
 	defer iter.Release()
 
 	if !iter.Next() || iter.Key() != key {
+		if r.filter != nil {
+			atomic.AddUint64(&r.stats.FalsePositives, 1)
+		}
 		return dst, ErrNotFound
 	}
+	if iter.Kind() == KindDelete {
+		return dst, ErrDeleted
+	}
 	return append(dst, iter.Value()...), nil
 }
 
 // Get is a shortcut for Append(nil, key).
-// It may return an ErrNotFound error.
+// It may return an ErrNotFound or an ErrDeleted error.
 func (r *Reader) Get(key uint64) ([]byte, error) {
 	return r.Append(nil, key)
 }
@@ -104,7 +291,57 @@ func (r *Reader) Seek(key uint64) (*Iterator, error) {
 
 	s := b.SeekSection(key)
 	s.Seek(key)
-	return &Iterator{r: r, b: b, s: s}, nil
+	return r.newIterator(b, s), nil
+}
+
+// NewIterator returns an iterator positioned before the first entry
+// within the (optional) bounds.
+func (r *Reader) NewIterator(o *IteratorOptions) (*Iterator, error) {
+	lo, hi, ok := o.limits()
+	if !ok {
+		iter, err := r.Last()
+		if err != nil {
+			return nil, err
+		}
+		iter.lo, iter.hi = math.MaxUint64, 0
+		return iter, nil
+	}
+
+	iter, err := r.Seek(lo)
+	if err != nil {
+		return nil, err
+	}
+	iter.lo, iter.hi = lo, hi
+	return iter, nil
+}
+
+// SeekLT returns an iterator positioned after the last entry < key.
+// Use Prev to iterate backwards.
+func (r *Reader) SeekLT(key uint64) (*Iterator, error) {
+	return r.Seek(key)
+}
+
+// SeekLE returns an iterator positioned after the last entry <= key.
+// Use Prev to iterate backwards.
+func (r *Reader) SeekLE(key uint64) (*Iterator, error) {
+	if key == math.MaxUint64 {
+		return r.Last()
+	}
+	return r.Seek(key + 1)
+}
+
+// Last returns an iterator positioned after the last entry.
+// Use Prev to iterate backwards.
+func (r *Reader) Last() (*Iterator, error) {
+	b, err := r.GetBlock(len(r.index))
+	if err != nil {
+		return nil, err
+	}
+	return r.newIterator(b, b.GetSection(0)), nil
+}
+
+func (r *Reader) newIterator(b *BlockReader, s *SectionReader) *Iterator {
+	return &Iterator{r: r, b: b, s: s, hi: math.MaxUint64}
 }
 
 // GetBlock returns a reader for the n-th block.
@@ -125,53 +362,146 @@ func (r *Reader) GetBlock(bpos int) (*BlockReader, error) {
 
 // SeekBlock seeks the block containing the key.
 func (r *Reader) SeekBlock(key uint64) (*BlockReader, error) {
-	bpos := sort.Search(len(r.index), func(i int) bool {
+	return r.GetBlock(r.searchBlock(key))
+}
+
+// searchBlock returns the position of the block containing the key.
+func (r *Reader) searchBlock(key uint64) int {
+	return sort.Search(len(r.index), func(i int) bool {
 		return r.index[i].MaxKey >= key
 	})
-	return r.GetBlock(bpos)
 }
 
 func (r *Reader) readBlock(bpos int) (*BlockReader, error) {
-	min := r.index[bpos].Offset
-	max := r.maxOffset
+	cache := r.o.BlockCache
+	if cache != nil {
+		if block, ok := cache.get(cacheKey{table: r.id, bpos: bpos}); ok {
+			return r.newBlockReader(bpos, block, false), nil
+		}
+	}
+
+	min, max := r.blockOffsets(bpos)
+	block, aliased, err := r.readRawBlock(bpos, min, max)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(block) < 4 {
+		r.releaseBlock(block, aliased)
+		return nil, &CorruptionError{Block: bpos, Offset: min, Reason: "block is too short"}
+	}
+	scnt := int(binary.LittleEndian.Uint32(block[len(block)-4:]))
+	if scnt < 1 || scnt > len(block)/4 {
+		r.releaseBlock(block, aliased)
+		return nil, &CorruptionError{Block: bpos, Offset: min, Reason: "bad section count"}
+	}
+
+	if aliased {
+		br := r.newBlockReader(bpos, block, false)
+		br.mm = r.mm
+		return br, nil
+	}
+	if cache != nil && cache.add(cacheKey{table: r.id, bpos: bpos}, block) {
+		return r.newBlockReader(bpos, block, false), nil
+	}
+	return r.newBlockReader(bpos, block, true), nil
+}
+
+// blockOffsets returns the start and end offsets of the block.
+func (r *Reader) blockOffsets(bpos int) (min, max int64) {
+	min, max = r.index[bpos].Offset, r.maxOffset
 	if next := bpos + 1; next < len(r.index) {
 		max = r.index[next].Offset
 	}
+	return
+}
 
-	raw := fetchBuffer(int(max - min))
-	if _, err := r.r.ReadAt(raw, min); err != nil {
-		releaseBuffer(raw)
-		return nil, err
+func (r *Reader) newBlockReader(bpos int, block []byte, pooled bool) *BlockReader {
+	return &BlockReader{
+		block:  block,
+		bpos:   bpos,
+		scnt:   int(binary.LittleEndian.Uint32(block[len(block)-4:])),
+		maxKey: r.index[bpos].MaxKey,
+		pooled: pooled,
+		kinds:  r.ft.Features&featureEntryKinds != 0,
 	}
+}
+
+// readRawBlock reads the block between min and max, verifies the checksum
+// and returns the decompressed block data. The returned block is either
+// a pooled buffer or, if aliased is true, a reference to the memory
+// mapping, in which case a mapping reference is held on behalf of the caller.
+func (r *Reader) readRawBlock(bpos int, min, max int64) (block []byte, aliased bool, err error) {
+	var raw []byte
+	if r.mm != nil {
+		if !r.mm.acquire() {
+			return nil, false, errClosed
+		}
+		if min < 0 || min > max || max > int64(len(r.mm.data)) {
+			r.mm.release()
+			return nil, false, &CorruptionError{Block: bpos, Offset: min, Reason: "block is out of bounds"}
+		}
+		raw, aliased = r.mm.data[min:max], true
+	} else {
+		raw = fetchBuffer(int(max - min))
+		if _, err := r.r.ReadAt(raw, min); err != nil {
+			releaseBuffer(raw)
+			return nil, false, err
+		}
+	}
+
+	if len(raw) == 0 {
+		r.releaseBlock(raw, aliased)
+		return nil, false, &CorruptionError{Block: bpos, Offset: min, Reason: "block is empty"}
+	}
+
+	// parse trailer
+	n := len(raw) - 1
+	ctype := raw[n]
+	if ctype&blockChecksumFlag != 0 {
+		if n < 4 {
+			r.releaseBlock(raw, aliased)
+			return nil, false, &CorruptionError{Block: bpos, Offset: min, Reason: "block is too short"}
+		}
+		n -= 4
 
-	var block []byte
-	switch cBitPos := len(raw) - 1; raw[cBitPos] {
+		if !r.o.SkipChecksums && blockChecksum(raw[:n], ctype) != binary.LittleEndian.Uint32(raw[n:]) {
+			r.releaseBlock(raw, aliased)
+			return nil, false, &CorruptionError{Block: bpos, Offset: min, Reason: "checksum mismatch"}
+		}
+		ctype &^= blockChecksumFlag
+	}
+
+	switch ctype {
 	case blockNoCompression:
-		block = raw[:cBitPos]
+		return raw[:n], aliased, nil
 	case blockSnappyCompression:
-		defer releaseBuffer(raw)
+		defer r.releaseBlock(raw, aliased)
 
-		sz, err := snappy.DecodedLen(raw[:cBitPos])
+		sz, err := snappy.DecodedLen(raw[:n])
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 
 		plain := fetchBuffer(sz)
-		if block, err = snappy.Decode(plain, raw[:cBitPos]); err != nil {
+		if block, err = snappy.Decode(plain, raw[:n]); err != nil {
 			releaseBuffer(plain)
-			return nil, err
+			return nil, false, err
 		}
+		return block, false, nil
 	default:
-		releaseBuffer(raw)
-		return nil, errBadCompression
+		r.releaseBlock(raw, aliased)
+		return nil, false, errBadCompression
 	}
+}
 
-	return &BlockReader{
-		block:  block,
-		bpos:   bpos,
-		scnt:   int(binary.LittleEndian.Uint32(block[len(block)-4:])),
-		maxKey: r.index[bpos].MaxKey,
-	}, nil
+// releaseBlock releases a block returned by readRawBlock.
+func (r *Reader) releaseBlock(block []byte, aliased bool) {
+	if aliased {
+		r.mm.release()
+	} else {
+		releaseBuffer(block)
+	}
 }
 
 // --------------------------------------------------------------------
@@ -182,8 +512,14 @@ type BlockReader struct {
 	bpos   int // the current block position
 	scnt   int // the section count
 	maxKey uint64
+	pooled bool     // true if the block buffer can be returned to the pool
+	mm     *mapping // the memory mapping the block references, if any
+	kinds  bool     // true if entries are encoded with kinds
 }
 
+// Size returns the (uncompressed) size of the block.
+func (r *BlockReader) Size() int { return len(r.block) }
+
 // NumSections returns the number of sections in this block.
 func (r *BlockReader) NumSections() int { return r.scnt }
 
@@ -196,12 +532,12 @@ func (r *BlockReader) GetSection(spos int) *SectionReader {
 		spos = 0
 	}
 	if spos >= r.scnt {
-		return newSectionReader(r.scnt, nil)
+		return newSectionReader(r.scnt, nil, r.kinds)
 	}
 
 	min := r.sectionOffset(spos)
 	max := r.sectionOffset(spos + 1)
-	return newSectionReader(spos, r.block[min:max])
+	return newSectionReader(spos, r.block[min:max], r.kinds)
 }
 
 // SeekSection seeks the section for a key.
@@ -211,16 +547,27 @@ func (r *BlockReader) SeekSection(key uint64) *SectionReader {
 	}
 
 	spos := sort.Search(r.scnt, func(i int) bool {
-		off := r.sectionOffset(i)
-		first, _ := binary.Uvarint(r.block[off:]) // first key of the section
-		return first > key
+		return r.firstKey(i) > key
 	}) - 1
 	return r.GetSection(spos)
 }
 
+// The first key of the section.
+func (r *BlockReader) firstKey(spos int) uint64 {
+	key, _ := binary.Uvarint(r.block[r.sectionOffset(spos):])
+	return key
+}
+
 // Release releases the block reader and frees up resources. The reader must not be used
 // after this method is called.
-func (r *BlockReader) Release() { bufPool.Put(r.block) }
+func (r *BlockReader) Release() {
+	if r.mm != nil {
+		r.mm.release()
+	} else if r.pooled {
+		releaseBuffer(r.block)
+	}
+	r.mm, r.pooled = nil, false
+}
 
 // The starting offset of the section within the block.
 func (r *BlockReader) sectionOffset(spos int) int {
@@ -240,20 +587,23 @@ var sectionReaderPool sync.Pool
 type SectionReader struct {
 	section []byte
 
-	spos int // the section
-	read int // bytes read
+	spos  int  // the section
+	read  int  // bytes read
+	n     int  // entries read
+	kinds bool // true if entries are encoded with kinds
 
-	key uint64 // current key
-	val []byte // current value
+	key  uint64 // current key
+	kind Kind   // current kind
+	val  []byte // current value
 }
 
-func newSectionReader(spos int, section []byte) *SectionReader {
+func newSectionReader(spos int, section []byte, kinds bool) *SectionReader {
 	if v := sectionReaderPool.Get(); v != nil {
 		sr := v.(*SectionReader)
-		*sr = SectionReader{spos: spos, section: section}
+		*sr = SectionReader{spos: spos, section: section, kinds: kinds}
 		return sr
 	}
-	return &SectionReader{spos: spos, section: section}
+	return &SectionReader{spos: spos, section: section, kinds: kinds}
 }
 
 // Seek positions the cursor before the key.
@@ -269,10 +619,7 @@ func (r *SectionReader) Seek(key uint64) bool {
 		}
 
 		if r.More() {
-			vln, n := binary.Uvarint(r.section[r.read:])
-			r.read += n
-			r.val = r.section[r.read : r.read+int(vln)]
-			r.read += int(vln)
+			r.readValue()
 		}
 	}
 	return false
@@ -284,6 +631,9 @@ func (r *SectionReader) Pos() int { return r.spos }
 // Key returns the key if the current entry.
 func (r *SectionReader) Key() uint64 { return r.key }
 
+// Kind returns the kind of the current entry.
+func (r *SectionReader) Kind() Kind { return r.kind }
+
 // Value returns the value of the current entry. Please note that values
 // are temporary buffers and must be copied if used beyond the next cursor move.
 func (r *SectionReader) Value() []byte { return r.val }
@@ -301,16 +651,53 @@ func (r *SectionReader) Next() bool {
 	}
 
 	if r.More() {
-		vln, n := binary.Uvarint(r.section[r.read:])
-		r.read += n
-		r.val = r.section[r.read : r.read+int(vln)]
-		r.read += int(vln)
+		r.readValue()
 		return true
 	}
 
 	return false
 }
 
+// readValue reads the kind and value of the current entry.
+func (r *SectionReader) readValue() {
+	vln, n := binary.Uvarint(r.section[r.read:])
+	r.read += n
+	if r.kinds {
+		r.kind = Kind(vln & 1)
+		vln >>= 1
+	}
+	r.val = r.section[r.read : r.read+int(vln)]
+	r.read += int(vln)
+	r.n++
+}
+
+// peek returns the next key without advancing the cursor.
+func (r *SectionReader) peek() uint64 {
+	inc, _ := binary.Uvarint(r.section[r.read:])
+	return r.key + inc
+}
+
+// seekEntry positions the cursor on the n-th entry within the section.
+// Since keys are delta-encoded, it needs to re-read the section from the
+// beginning. A negative n positions the cursor before the first entry.
+func (r *SectionReader) seekEntry(n int) bool {
+	r.read, r.n, r.key, r.kind, r.val = 0, 0, 0, KindPut, nil
+	for r.n <= n {
+		if !r.Next() {
+			return false
+		}
+	}
+	return true
+}
+
+// seekLast positions the cursor on the last entry within the section.
+func (r *SectionReader) seekLast() bool {
+	r.read, r.n, r.key, r.kind, r.val = 0, 0, 0, KindPut, nil
+	for r.Next() {
+	}
+	return r.n != 0
+}
+
 // Release releases the section reader and frees up resources. The reader must not be used
 // after this method is called.
 func (r *SectionReader) Release() { sectionReaderPool.Put(r) }
@@ -318,18 +705,23 @@ func (r *SectionReader) Release() { sectionReaderPool.Put(r) }
 // --------------------------------------------------------------------
 
 // Iterator is a convenience wrapper around BlockReader and SectionReader
-// which can (forward-) iterate over keys across block and section boundaries.
+// which can iterate over keys across block and section boundaries.
 type Iterator struct {
 	r *Reader
 	b *BlockReader
 	s *SectionReader
 
-	err error
+	lo, hi uint64 // inclusive key limits
+	at     bool   // true if positioned on an entry
+	err    error
 }
 
 // Key returns the key if the current entry.
 func (i *Iterator) Key() uint64 { return i.s.Key() }
 
+// Kind returns the kind of the current entry.
+func (i *Iterator) Kind() Kind { return i.s.Kind() }
+
 // Value returns the value of the current entry. Please note that values
 // are temporary buffers and must be copied if used beyond the next cursor move.
 func (i *Iterator) Value() []byte { return i.s.Value() }
@@ -340,36 +732,120 @@ func (i *Iterator) More() bool {
 		return false
 	}
 
-	return i.s.More() || i.s.Pos()+1 < i.b.NumSections() || i.b.Pos()+1 < i.r.NumBlocks()
+	if i.s.More() {
+		return i.s.peek() <= i.hi
+	}
+	if n := i.s.Pos() + 1; n < i.b.NumSections() {
+		return i.b.firstKey(n) <= i.hi
+	}
+	if n := i.b.Pos() + 1; n < i.r.NumBlocks() {
+		return i.r.index[n-1].MaxKey < i.hi
+	}
+	return false
 }
 
 // Next advances the cursor to the next entry and returns true if successful.
 func (i *Iterator) Next() bool {
+	i.at = i.next()
+	return i.at
+}
+
+func (i *Iterator) next() bool {
 	if i.err != nil {
 		return false
 	}
 
 	// more entries in the section
 	if i.s.More() {
-		return i.s.Next()
+		return i.advance()
 	}
 
 	// more sections in the block
 	if n := i.s.Pos() + 1; n < i.b.NumSections() {
-		i.s = i.b.GetSection(n)
-		return i.s.Next()
+		i.setSection(i.b.GetSection(n))
+		return i.advance()
 	}
 
-	// more blocks
-	if n := i.b.Pos() + 1; n < i.r.NumBlocks() {
-		i.b, i.err = i.r.GetBlock(n)
-		i.s = i.b.GetSection(0)
-		return i.s.Next()
+	// more blocks, unless they start beyond the upper limit
+	if n := i.b.Pos() + 1; n < i.r.NumBlocks() && i.r.index[n-1].MaxKey < i.hi {
+		if !i.setBlock(n) {
+			return false
+		}
+		i.setSection(i.b.GetSection(0))
+		return i.advance()
+	}
+
+	return false
+}
+
+// advance advances the section cursor unless the next key is beyond the
+// upper limit.
+func (i *Iterator) advance() bool {
+	if i.s.peek() > i.hi {
+		return false
+	}
+	return i.s.Next()
+}
+
+// Prev moves the cursor to the previous entry and returns true if successful.
+// After a Seek, the previous entry is the last entry before the seeked
+// position.
+func (i *Iterator) Prev() bool {
+	i.at = i.prev()
+	return i.at
+}
+
+func (i *Iterator) prev() bool {
+	if i.err != nil {
+		return false
 	}
 
+	// previous entries in the section
+	n := i.s.n - 1
+	if i.at {
+		n--
+	}
+	if n >= 0 {
+		return i.s.seekEntry(n) && i.s.Key() >= i.lo
+	}
+
+	// previous sections in the block
+	if n := i.s.Pos() - 1; n >= 0 && n < i.b.NumSections() {
+		i.setSection(i.b.GetSection(n))
+		return i.s.seekLast() && i.s.Key() >= i.lo
+	}
+
+	// previous blocks, unless they end below the lower limit
+	if n := i.b.Pos() - 1; n >= 0 && i.r.index[n].MaxKey >= i.lo {
+		if !i.setBlock(n) {
+			return false
+		}
+		i.setSection(i.b.GetSection(i.b.NumSections() - 1))
+		return i.s.seekLast() && i.s.Key() >= i.lo
+	}
+
+	// position before the first entry
+	i.s.seekEntry(-1)
 	return false
 }
 
+func (i *Iterator) setBlock(bpos int) bool {
+	b, err := i.r.GetBlock(bpos)
+	if err != nil {
+		i.err = err
+		return false
+	}
+
+	i.b.Release()
+	i.b = b
+	return true
+}
+
+func (i *Iterator) setSection(s *SectionReader) {
+	i.s.Release()
+	i.s = s
+}
+
 // Err exposes iterator errors, if any.
 func (i *Iterator) Err() error {
 	return i.err
@@ -1,7 +1,9 @@
 package sntable_test
 
 import (
+	"bytes"
 	"fmt"
+	"math"
 
 	"github.com/bsm/sntable"
 	. "github.com/onsi/ginkgo"
@@ -53,6 +55,141 @@ var _ = Describe("Reader", func() {
 		Expect(err).To(MatchError(sntable.ErrNotFound))
 	})
 
+	It("should read legacy tables", func() {
+		data := []byte{
+			7, 3, 'f', 'o', 'o', 1, 3, 'b', 'a', 'r', // section
+			1, 0, 0, 0, // section count
+			0,    // compression type
+			8, 0, // block index
+			15, 0, 0, 0, 0, 0, 0, 0, // index offset
+			71, 39, 134, 190, 31, 122, 101, 219, // magic
+		}
+
+		tr, err := sntable.NewReader(bytes.NewReader(data), int64(len(data)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tr.NumBlocks()).To(Equal(1))
+		Expect(tr.Get(7)).To(Equal([]byte("foo")))
+		Expect(tr.Get(8)).To(Equal([]byte("bar")))
+	})
+
+	It("should reject unsupported formats", func() {
+		buf := new(bytes.Buffer)
+		Expect(seedTable(buf, 10)).To(Succeed())
+
+		data := append([]byte{}, buf.Bytes()...)
+		data[len(data)-12] = 9 // format version
+		_, err := sntable.NewReader(bytes.NewReader(data), int64(len(data)))
+		Expect(err).To(MatchError(`sntable: unsupported format version 9`))
+
+		data = append([]byte{}, buf.Bytes()...)
+		data[len(data)-14] = 4 // feature flags
+		_, err = sntable.NewReader(bytes.NewReader(data), int64(len(data)))
+		Expect(err).To(MatchError(`sntable: unsupported feature flags 0x40000`))
+
+		data = append([]byte{}, buf.Bytes()...)
+		data[len(data)-1] = 0
+		_, err = sntable.NewReader(bytes.NewReader(data), int64(len(data)))
+		Expect(err).To(MatchError(`sntable: bad magic byte sequence`))
+	})
+
+	It("should verify checksums", func() {
+		buf := new(bytes.Buffer)
+		Expect(seedTable(buf, 100)).To(Succeed())
+
+		data := buf.Bytes()
+		data[10] ^= 0xff
+
+		tr, err := sntable.NewReader(bytes.NewReader(data), int64(len(data)))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = tr.Get(4)
+		Expect(err).To(MatchError(`sntable: corrupt block #0 at offset 0: checksum mismatch`))
+		Expect(err).To(BeAssignableToTypeOf(&sntable.CorruptionError{}))
+		Expect(tr.Get(124)).To(HaveSuffix("0124"))
+
+		tr, err = sntable.NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), &sntable.ReaderOptions{SkipChecksums: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tr.Get(4)).To(HaveSuffix("0004"))
+	})
+
+	It("should use filters", func() {
+		buf := new(bytes.Buffer)
+		tw := sntable.NewWriter(buf, &sntable.WriterOptions{FilterBitsPerKey: 10})
+		for key := uint64(0); key < 40000; key += 4 {
+			Expect(tw.Append(key, []byte("testdata"))).To(Succeed())
+		}
+		Expect(tw.Close()).To(Succeed())
+
+		tr, err := sntable.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tr.Get(4000)).To(Equal([]byte("testdata")))
+		Expect(tr.FilterStats()).To(Equal(sntable.FilterStats{}))
+
+		for key := uint64(1); key < 39996; key += 4 {
+			_, err := tr.Get(key)
+			Expect(err).To(MatchError(sntable.ErrNotFound))
+		}
+		stats := tr.FilterStats()
+		Expect(stats.Hits + stats.FalsePositives).To(Equal(uint64(9999)))
+		Expect(stats.FalsePositives).To(BeNumerically("<", 300))
+	})
+
+	It("should read tombstones", func() {
+		buf := new(bytes.Buffer)
+		tw := sntable.NewWriter(buf, &sntable.WriterOptions{FilterBitsPerKey: 10})
+		for key := uint64(0); key < 1000; key += 4 {
+			if key%3 == 0 {
+				Expect(tw.Delete(key)).To(Succeed())
+			} else {
+				Expect(tw.Append(key, []byte("testdata"))).To(Succeed())
+			}
+		}
+		Expect(tw.Delete(1002)).To(Succeed())
+		Expect(tw.Delete(1002)).To(MatchError(`sntable: attempted an out-of-order append, 1002 must be > 1002`))
+		Expect(tw.Close()).To(Succeed())
+
+		tr, err := sntable.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tr.Footer().Features).To(Equal(uint32(1)))
+		Expect(tr.Properties().NumEntries).To(Equal(uint64(251)))
+		Expect(tr.Properties().NumDeletions).To(Equal(uint64(85)))
+
+		Expect(tr.Get(4)).To(Equal([]byte("testdata")))
+		_, err = tr.Get(12)
+		Expect(err).To(MatchError(sntable.ErrDeleted))
+		_, err = tr.Get(1002)
+		Expect(err).To(MatchError(sntable.ErrDeleted))
+		_, err = tr.Get(13)
+		Expect(err).To(MatchError(sntable.ErrNotFound))
+
+		iter, err := tr.Seek(0)
+		Expect(err).NotTo(HaveOccurred())
+		defer iter.Release()
+
+		var n int
+		for iter.Next() {
+			if iter.Key()%3 == 0 {
+				Expect(iter.Kind()).To(Equal(sntable.KindDelete), "for %d", iter.Key())
+				Expect(iter.Value()).To(BeEmpty())
+			} else {
+				Expect(iter.Kind()).To(Equal(sntable.KindPut), "for %d", iter.Key())
+				Expect(iter.Value()).To(Equal([]byte("testdata")))
+			}
+			n++
+		}
+		Expect(iter.Err()).NotTo(HaveOccurred())
+		Expect(n).To(Equal(251))
+
+		for iter.Prev() {
+			if iter.Key()%3 == 0 {
+				Expect(iter.Kind()).To(Equal(sntable.KindDelete), "for %d", iter.Key())
+			} else {
+				Expect(iter.Kind()).To(Equal(sntable.KindPut), "for %d", iter.Key())
+			}
+		}
+		Expect(iter.Err()).NotTo(HaveOccurred())
+	})
+
 	It("should retrieve blocks", func() {
 		b0, err := subject.GetBlock(0)
 		Expect(err).NotTo(HaveOccurred())
@@ -222,6 +359,172 @@ var _ = Describe("Reader", func() {
 			Expect(iter.Err()).NotTo(HaveOccurred())
 		})
 
+		It("should iterate backwards", func() {
+			iter, err := subject.Last()
+			Expect(err).NotTo(HaveOccurred())
+			defer iter.Release()
+
+			for key := 396; key >= 0; key -= 4 {
+				Expect(iter.Prev()).To(BeTrue(), "for %d", key)
+				Expect(iter.Key()).To(Equal(uint64(key)))
+				Expect(iter.Value()).To(HaveSuffix(fmt.Sprintf("%04d", key)))
+			}
+			Expect(iter.Prev()).To(BeFalse())
+			Expect(iter.Prev()).To(BeFalse())
+			Expect(iter.Err()).NotTo(HaveOccurred())
+
+			Expect(iter.Next()).To(BeTrue())
+			Expect(iter.Key()).To(Equal(uint64(0)))
+		})
+
+		It("should change directions", func() {
+			iter, err := subject.Seek(184)
+			Expect(err).NotTo(HaveOccurred())
+			defer iter.Release()
+
+			Expect(iter.Next()).To(BeTrue())
+			Expect(iter.Key()).To(Equal(uint64(184)))
+			Expect(iter.Next()).To(BeTrue())
+			Expect(iter.Key()).To(Equal(uint64(188)))
+			Expect(iter.Prev()).To(BeTrue())
+			Expect(iter.Key()).To(Equal(uint64(184)))
+			Expect(iter.Prev()).To(BeTrue())
+			Expect(iter.Key()).To(Equal(uint64(180)))
+			Expect(iter.Next()).To(BeTrue())
+			Expect(iter.Key()).To(Equal(uint64(184)))
+
+			for iter.Next() {
+			}
+			Expect(iter.Prev()).To(BeTrue())
+			Expect(iter.Key()).To(Equal(uint64(396)))
+			Expect(iter.Prev()).To(BeTrue())
+			Expect(iter.Key()).To(Equal(uint64(392)))
+		})
+
+		It("should seek backwards", func() {
+			seekLT := func(key uint64) int {
+				iter, err := subject.SeekLT(key)
+				Expect(err).NotTo(HaveOccurred())
+				defer iter.Release()
+
+				if !iter.Prev() {
+					return -1
+				}
+				return int(iter.Key())
+			}
+			seekLE := func(key uint64) int {
+				iter, err := subject.SeekLE(key)
+				Expect(err).NotTo(HaveOccurred())
+				defer iter.Release()
+
+				if !iter.Prev() {
+					return -1
+				}
+				return int(iter.Key())
+			}
+
+			Expect(seekLT(200)).To(Equal(196))
+			Expect(seekLT(201)).To(Equal(200))
+			Expect(seekLT(124)).To(Equal(120))
+			Expect(seekLT(188)).To(Equal(184))
+			Expect(seekLT(1000)).To(Equal(396))
+			Expect(seekLT(0)).To(Equal(-1))
+
+			Expect(seekLE(200)).To(Equal(200))
+			Expect(seekLE(199)).To(Equal(196))
+			Expect(seekLE(124)).To(Equal(124))
+			Expect(seekLE(123)).To(Equal(120))
+			Expect(seekLE(0)).To(Equal(0))
+			Expect(seekLE(math.MaxUint64)).To(Equal(396))
+		})
+
+		It("should iterate within bounds", func() {
+			collect := func(o *sntable.IteratorOptions) []uint64 {
+				iter, err := subject.NewIterator(o)
+				Expect(err).NotTo(HaveOccurred())
+				defer iter.Release()
+
+				var keys []uint64
+				for iter.Next() {
+					keys = append(keys, iter.Key())
+				}
+				Expect(iter.More()).To(BeFalse())
+				Expect(iter.Err()).NotTo(HaveOccurred())
+				return keys
+			}
+
+			Expect(collect(nil)).To(HaveLen(100))
+			Expect(collect(&sntable.IteratorOptions{
+				LowerBound: sntable.Inclusive(116),
+				UpperBound: sntable.Inclusive(128),
+			})).To(Equal([]uint64{116, 120, 124, 128}))
+			Expect(collect(&sntable.IteratorOptions{
+				LowerBound: sntable.Exclusive(116),
+				UpperBound: sntable.Exclusive(128),
+			})).To(Equal([]uint64{120, 124}))
+			Expect(collect(&sntable.IteratorOptions{
+				LowerBound: sntable.Inclusive(117),
+				UpperBound: sntable.Inclusive(127),
+			})).To(Equal([]uint64{120, 124}))
+			Expect(collect(&sntable.IteratorOptions{
+				LowerBound: sntable.Inclusive(388),
+			})).To(Equal([]uint64{388, 392, 396}))
+			Expect(collect(&sntable.IteratorOptions{
+				UpperBound: sntable.Exclusive(12),
+			})).To(Equal([]uint64{0, 4, 8}))
+			Expect(collect(&sntable.IteratorOptions{
+				LowerBound: sntable.Inclusive(150),
+				UpperBound: sntable.Inclusive(100),
+			})).To(BeEmpty())
+			Expect(collect(&sntable.IteratorOptions{
+				UpperBound: sntable.Exclusive(0),
+			})).To(BeEmpty())
+		})
+
+		It("should iterate backwards within bounds", func() {
+			iter, err := subject.NewIterator(&sntable.IteratorOptions{
+				LowerBound: sntable.Inclusive(116),
+				UpperBound: sntable.Inclusive(128),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			defer iter.Release()
+
+			Expect(iter.Prev()).To(BeFalse())
+			for iter.Next() {
+			}
+
+			var keys []uint64
+			for iter.Prev() {
+				keys = append(keys, iter.Key())
+			}
+			Expect(keys).To(Equal([]uint64{128, 124, 120, 116}))
+			Expect(iter.Prev()).To(BeFalse())
+			Expect(iter.Next()).To(BeTrue())
+			Expect(iter.Key()).To(Equal(uint64(116)))
+		})
+
+		It("should not read blocks beyond bounds", func() {
+			buf := new(bytes.Buffer)
+			Expect(seedTable(buf, 100)).To(Succeed())
+
+			ra := &countingReaderAt{ReaderAt: bytes.NewReader(buf.Bytes())}
+			tr, err := sntable.NewReader(ra, int64(buf.Len()))
+			Expect(err).NotTo(HaveOccurred())
+			ra.N = 0
+
+			iter, err := tr.NewIterator(&sntable.IteratorOptions{
+				LowerBound: sntable.Inclusive(200),
+				UpperBound: sntable.Inclusive(244),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			defer iter.Release()
+
+			for iter.Next() {
+			}
+			Expect(iter.Key()).To(Equal(uint64(244)))
+			Expect(ra.N).To(Equal(1))
+		})
+
 		It("should not iterate when past the end", func() {
 			iter, err := subject.Seek(1000)
 			Expect(err).NotTo(HaveOccurred())
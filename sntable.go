@@ -1,29 +1,95 @@
 package sntable
 
-import "errors"
-
-var magic = []byte{71, 39, 134, 190, 31, 122, 101, 219}
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
 
 const (
 	blockNoCompression     = 0
 	blockSnappyCompression = 1
+
+	// blockChecksumFlag is set on the compression type byte of blocks
+	// which are followed by a CRC32C checksum.
+	blockChecksumFlag = 0x80
 )
 
 // ErrNotFound is returned by the reader when a key cannot be found.
 var ErrNotFound = errors.New("sntable: not found")
 
+// ErrDeleted is returned by the reader when a key was deleted.
+var ErrDeleted = errors.New("sntable: deleted")
+
 var (
 	errClosed         = errors.New("sntable: is closed")
 	errBadMagic       = errors.New("sntable: bad magic byte sequence")
+	errBadFooter      = errors.New("sntable: bad footer")
+	errBadMetaIndex   = errors.New("sntable: bad metaindex")
+	errBadFilter      = errors.New("sntable: bad filter block")
+	errBadProperties  = errors.New("sntable: bad properties block")
 	errBadCompression = errors.New("sntable: bad compression codec")
 	errReleased       = errors.New("sntable: iterator was released")
 )
 
-type blockInfo struct {
+// CorruptionError is returned when a block fails integrity checks.
+type CorruptionError struct {
+	Block  int    // the block position, -1 for meta blocks
+	Offset int64  // the block offset within the table
+	Reason string // the reason
+}
+
+func (e *CorruptionError) Error() string {
+	if e.Block < 0 {
+		return fmt.Sprintf("sntable: corrupt meta block at offset %d: %s", e.Offset, e.Reason)
+	}
+	return fmt.Sprintf("sntable: corrupt block #%d at offset %d: %s", e.Block, e.Offset, e.Reason)
+}
+
+type indexEntry struct {
 	MaxKey uint64 // maximum key in the block
 	Offset int64  // block offset position
 }
 
+// BlockInfo contains information about a data block.
+type BlockInfo struct {
+	MaxKey uint64 // the maximum key in the block
+	Offset int64  // the block offset
+	Length int64  // the stored block length, including the trailer
+}
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// blockChecksum calculates the CRC32C checksum of the block data and
+// the compression type byte.
+func blockChecksum(data []byte, ctype byte) uint32 {
+	crc := crc32.Update(0, crcTable, data)
+	return crc32.Update(crc, crcTable, []byte{ctype})
+}
+
+// --------------------------------------------------------------------
+
+// Kind is the entry kind.
+type Kind byte
+
+// Supported entry kinds.
+const (
+	// KindPut marks regular entries.
+	KindPut Kind = iota
+	// KindDelete marks deleted entries (tombstones).
+	KindDelete
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindPut:
+		return "put"
+	case KindDelete:
+		return "delete"
+	}
+	return fmt.Sprintf("unknown (%d)", byte(k))
+}
+
 // --------------------------------------------------------------------
 
 // Compression is the compression codec
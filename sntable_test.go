@@ -3,7 +3,9 @@ package sntable_test
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"math/rand"
+	"sort"
 	"testing"
 
 	"github.com/bsm/sntable"
@@ -46,3 +48,39 @@ func seedTable(buf *bytes.Buffer, sz int) error {
 	}
 	return twr.Close()
 }
+
+func mapReader(vals map[uint64]string, deleted ...uint64) (*sntable.Reader, error) {
+	keys := append([]uint64{}, deleted...)
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	buf := new(bytes.Buffer)
+	twr := sntable.NewWriter(buf, &sntable.WriterOptions{BlockSize: 16})
+	for _, k := range keys {
+		var err error
+		if v, ok := vals[k]; ok {
+			err = twr.Append(k, []byte(v))
+		} else {
+			err = twr.Delete(k)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := twr.Close(); err != nil {
+		return nil, err
+	}
+	return sntable.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+}
+
+type countingReaderAt struct {
+	io.ReaderAt
+	N int
+}
+
+func (r *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	r.N++
+	return r.ReaderAt.ReadAt(p, off)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/golang/snappy"
 )
@@ -23,6 +24,17 @@ type WriterOptions struct {
 	// The compression codec to use.
 	// Default: SnappyCompression.
 	Compression Compression
+
+	// FilterBitsPerKey enables bloom filters when set to a positive value.
+	// Filters allow readers to avoid block reads for the majority of missing
+	// keys. 10 bits per key result in a false positive rate of ~1%.
+	// Default: 0 (disabled).
+	FilterBitsPerKey int
+
+	// UserProperties are stored in the table and can be retrieved
+	// by readers via Reader.Properties. Names with a "sntable." prefix
+	// are reserved and will be ignored.
+	UserProperties map[string]string
 }
 
 func (o *WriterOptions) norm() *WriterOptions {
@@ -49,28 +61,47 @@ type Writer struct {
 	w io.Writer
 	o *WriterOptions
 
-	block blockInfo // the current block info
-	blen  int       // the number of entries in the current block
-	soffs []int     // section offsets in the current block
+	block indexEntry // the current block info
+	blen  int        // the number of entries in the current block
+	soffs []int      // section offsets in the current block
 
 	buf []byte // plain buffer
 	snp []byte // snappy  buffer
 	tmp []byte // scratch buffer
 
-	index []blockInfo
+	index  []indexEntry
+	meta   metaIndex
+	filter *filterWriter
+	props  Properties
 }
 
 // NewWriter wraps a writer and returns a Writer.
 func NewWriter(w io.Writer, o *WriterOptions) *Writer {
-	return &Writer{
-		w:   w,
-		o:   o.norm(),
-		tmp: make([]byte, 2*binary.MaxVarintLen64),
+	wr := &Writer{
+		w:    w,
+		o:    o.norm(),
+		tmp:  make([]byte, 2*binary.MaxVarintLen64),
+		meta: make(metaIndex),
+	}
+	if wr.o.FilterBitsPerKey > 0 {
+		wr.filter = &filterWriter{bitsPerKey: wr.o.FilterBitsPerKey}
 	}
+	return wr
 }
 
 // Append appends a cell to the store.
 func (w *Writer) Append(key uint64, value []byte) error {
+	return w.append(key, KindPut, value)
+}
+
+// Delete appends a deletion marker (tombstone) for a key to the store.
+// Deleted keys shadow the values of the same key in other tables when
+// tables are merged or compacted.
+func (w *Writer) Delete(key uint64) error {
+	return w.append(key, KindDelete, nil)
+}
+
+func (w *Writer) append(key uint64, kind Kind, value []byte) error {
 	if w.tmp == nil {
 		return errClosed
 	}
@@ -92,11 +123,25 @@ func (w *Writer) Append(key uint64, value []byte) error {
 		skey -= w.block.MaxKey // apply delta-encoding
 	}
 
-	n := binary.PutUvarint(w.tmp[0:], uint64(skey))
-	n += binary.PutUvarint(w.tmp[n:], uint64(len(value)))
+	nk := binary.PutUvarint(w.tmp[0:], uint64(skey))
+	n := nk + binary.PutUvarint(w.tmp[nk:], uint64(len(value))<<1|uint64(kind))
 	w.buf = append(w.buf, w.tmp[:n]...)
 	w.buf = append(w.buf, value...)
 
+	if w.filter != nil {
+		w.filter.Add(key)
+	}
+
+	if w.props.NumEntries == 0 {
+		w.props.MinKey = key
+	}
+	w.props.NumEntries++
+	if kind == KindDelete {
+		w.props.NumDeletions++
+	}
+	w.props.RawKeySize += uint64(nk)
+	w.props.RawValueSize += uint64(len(value))
+
 	w.blen++
 	w.block.MaxKey = key
 
@@ -112,12 +157,31 @@ func (w *Writer) Close() error {
 		return err
 	}
 
-	indexOffset := w.block.Offset
+	dataSize := w.block.Offset
+
+	if w.filter != nil {
+		if err := w.writeMeta(filterBloomName, w.filter.Finish()); err != nil {
+			return err
+		}
+	}
+
+	if err := w.writeMeta(propertiesName, w.properties(dataSize).appendTo(nil)); err != nil {
+		return err
+	}
+
+	ft := Footer{Version: formatVersion, Features: featureEntryKinds}
+
+	ft.MetaIndexOffset = w.block.Offset
+	if err := w.writeRaw(w.meta.appendTo(nil)); err != nil {
+		return err
+	}
+
+	ft.IndexOffset = w.block.Offset
 	if err := w.writeIndex(); err != nil {
 		return err
 	}
 
-	if err := w.writeFooter(indexOffset); err != nil {
+	if err := w.writeRaw(ft.appendTo(nil)); err != nil {
 		return err
 	}
 	w.tmp = nil
@@ -125,7 +189,7 @@ func (w *Writer) Close() error {
 }
 
 func (w *Writer) writeIndex() error {
-	var prev blockInfo
+	var prev indexEntry
 
 	for i, ent := range w.index {
 		key := ent.MaxKey
@@ -146,17 +210,40 @@ func (w *Writer) writeIndex() error {
 	return nil
 }
 
-func (w *Writer) writeFooter(indexOffset int64) error {
-	binary.LittleEndian.PutUint64(w.tmp[0:], uint64(indexOffset))
-	if err := w.writeRaw(w.tmp[:8]); err != nil {
-		return err
-	}
-	if err := w.writeRaw(magic); err != nil {
+func (w *Writer) properties(dataSize int64) *Properties {
+	p := w.props
+	p.NumBlocks = uint64(len(w.index))
+	p.MaxKey = w.block.MaxKey
+	p.DataSize = uint64(dataSize)
+	p.Compression = w.o.Compression
+	p.BlockSize = w.o.BlockSize
+	p.BlockRestartInterval = w.o.BlockRestartInterval
+	p.FilterBitsPerKey = w.o.FilterBitsPerKey
+	p.CreatedAt = time.Now()
+	p.UserProperties = w.o.UserProperties
+	return &p
+}
+
+func (w *Writer) writeMeta(name string, data []byte) error {
+	offset := w.block.Offset
+	if err := w.writeBlock(data, blockNoCompression); err != nil {
 		return err
 	}
+
+	w.meta[name] = blockHandle{Offset: offset, Length: w.block.Offset - offset}
 	return nil
 }
 
+// writeBlock appends the checksum and the compression type to the
+// block data and writes the result.
+func (w *Writer) writeBlock(data []byte, ctype byte) error {
+	ctype |= blockChecksumFlag
+	binary.LittleEndian.PutUint32(w.tmp, blockChecksum(data, ctype))
+	data = append(data, w.tmp[:4]...)
+	data = append(data, ctype)
+	return w.writeRaw(data)
+}
+
 func (w *Writer) writeRaw(p []byte) error {
 	n, err := w.w.Write(p)
 	w.block.Offset += int64(n)
@@ -176,18 +263,24 @@ func (w *Writer) flush() error {
 	}
 	binary.LittleEndian.PutUint32(w.tmp, uint32(len(w.soffs)))
 	w.buf = append(w.buf, w.tmp[:4]...)
+	w.props.RawDataSize += uint64(len(w.buf))
 
 	var block []byte
+	var ctype byte
 	switch w.o.Compression {
 	case SnappyCompression:
 		w.snp = snappy.Encode(w.snp[:cap(w.snp)], w.buf)
 		if len(w.snp) < len(w.buf)-len(w.buf)/4 {
-			block = append(w.snp, blockSnappyCompression)
+			block, ctype = w.snp, blockSnappyCompression
 		} else {
-			block = append(w.buf, blockNoCompression)
+			block, ctype = w.buf, blockNoCompression
 		}
 	default:
-		block = append(w.buf, blockNoCompression)
+		block, ctype = w.buf, blockNoCompression
+	}
+
+	if w.filter != nil {
+		w.filter.Flush()
 	}
 
 	w.index = append(w.index, w.block)
@@ -195,5 +288,5 @@ func (w *Writer) flush() error {
 	w.soffs = w.soffs[:0]
 	w.blen = 0
 
-	return w.writeRaw(block)
+	return w.writeBlock(block, ctype)
 }
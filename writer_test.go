@@ -25,7 +25,8 @@ var _ = Describe("Writer", func() {
 
 	It("should write empty", func() {
 		Expect(subject.Close()).To(Succeed())
-		Expect(buf.Len()).To(Equal(16))
+		Expect(buf.Len()).To(Equal(382))
+		Expect(buf.String()[buf.Len()-8:]).To(Equal("\x5E\x91\x2B\xC4\x0D\x73\xA8\xF6"))
 	})
 
 	It("should prevent out-of-order appends", func() {
@@ -48,8 +49,8 @@ var _ = Describe("Writer", func() {
 			Expect(subject.Append(key, val)).To(Succeed())
 		}
 		Expect(subject.Close()).To(Succeed())
-		Expect(buf.Len()).To(BeNumerically("~", 6575289, 1024))
-		Expect(buf.String()[buf.Len()-8:]).To(Equal("\x47\x27\x86\xBE\x1F\x7a\x65\xDB"))
+		Expect(buf.Len()).To(BeNumerically("~", 6581741, 1024))
+		Expect(buf.String()[buf.Len()-8:]).To(Equal("\x5E\x91\x2B\xC4\x0D\x73\xA8\xF6"))
 	})
 
 	It("should write (well-compressable)", func() {
@@ -58,7 +59,7 @@ var _ = Describe("Writer", func() {
 			Expect(subject.Append(key, val)).To(Succeed())
 		}
 		Expect(subject.Close()).To(Succeed())
-		Expect(buf.Len()).To(BeNumerically("~", 362538, 1024))
-		Expect(buf.String()[buf.Len()-8:]).To(Equal("\x47\x27\x86\xBE\x1F\x7a\x65\xDB"))
+		Expect(buf.Len()).To(BeNumerically("~", 368990, 1024))
+		Expect(buf.String()[buf.Len()-8:]).To(Equal("\x5E\x91\x2B\xC4\x0D\x73\xA8\xF6"))
 	})
 })